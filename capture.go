@@ -0,0 +1,31 @@
+package glob
+
+import (
+	"github.com/gopherlib/simple-glob/match"
+	"github.com/gopherlib/simple-glob/syntax/ast"
+)
+
+func (g glob) Captures(s string) ([]string, bool) {
+	m := g.capturer
+	if m == nil {
+		m = g.matcher
+	}
+	captures, ok := match.Capture(m, s, nil)
+	if !ok {
+		return nil, false
+	}
+	return captures, true
+}
+
+// hasAlternation reports whether the tree contains an alternation.
+func hasAlternation(tree *ast.Node) bool {
+	if tree.Kind == ast.KindAnyOf {
+		return true
+	}
+	for _, c := range tree.Children {
+		if hasAlternation(c) {
+			return true
+		}
+	}
+	return false
+}
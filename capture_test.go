@@ -0,0 +1,51 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCaptures(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		fixture    string
+		captures   []string
+		ok         bool
+	}{
+		{pattern: "*.github.com", fixture: "api.github.com", captures: []string{"api"}, ok: true},
+		{pattern: "*.github.com", fixture: "api.gitlab.com", ok: false},
+		{pattern: "api.*", fixture: "api.github.com", captures: []string{"github.com"}, ok: true},
+		{pattern: "abc", fixture: "abc", captures: nil, ok: true},
+		{pattern: "*.*", fixture: "a.b.c", captures: []string{"a.b", "c"}, ok: true},
+		{pattern: "*.*", fixture: "a.b.c", delimiters: []rune{'/'}, captures: []string{"a.b", "c"}, ok: true},
+		{pattern: "*/*.jpeg", fixture: "photos/cat.jpeg", delimiters: []rune{'/'}, captures: []string{"photos", "cat"}, ok: true},
+		{pattern: "*//*.example.com", fixture: "https://www.example.com", delimiters: []rune{'.'}, captures: []string{"https:", "www"}, ok: true},
+		{pattern: "a?c*", fixture: "abcdef", features: Single, captures: []string{"b", "def"}, ok: true},
+		{pattern: "??", fixture: "日本", features: Single, captures: []string{"日", "本"}, ok: true},
+		{pattern: "*?", fixture: "\xff", features: Single, captures: []string{"", "\xff"}, ok: true},
+		{pattern: "*[!a]*", fixture: "\xff", features: Classes, captures: []string{"", "\xff", ""}, ok: true},
+		{pattern: "[a-z][0-9]-*", fixture: "x7-rest", features: Classes, captures: []string{"x", "7", "rest"}, ok: true},
+		{pattern: "/**/*.go", fixture: "/src/pkg/main.go", delimiters: []rune{'/'}, features: Super, captures: []string{"src/pkg", "main"}, ok: true},
+		{pattern: "*.{jpg,jpeg}", fixture: "cat.jpeg", features: Alternates, captures: []string{"cat", "jpeg"}, ok: true},
+		{pattern: "{api.prod,api.staging}.*", fixture: "api.staging.com", features: Alternates, captures: []string{"api.staging", "com"}, ok: true},
+		{pattern: "x{api.prod,api.staging}.example.com", fixture: "xapi.prod.example.com", features: Alternates, captures: []string{"api.prod"}, ok: true},
+		{pattern: "{*.jpg,*.jpeg}", fixture: "cat.jpg", features: Alternates, captures: []string{"cat.jpg"}, ok: true},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+
+			captures, ok := g.Captures(test.fixture)
+			if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+				t.Errorf(
+					"Captures(%q) = %q, %v; want %q, %v\n%s",
+					test.fixture, captures, ok, test.captures, test.ok, g,
+				)
+			}
+			if ok != g.Match(test.fixture) {
+				t.Errorf("Captures(%q) reports %v while Match() does not", test.fixture, ok)
+			}
+		})
+	}
+}
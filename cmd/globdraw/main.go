@@ -7,9 +7,9 @@ import (
 	"strings"
 	"unicode/utf8"
 
-	"github.com/gopherlib/simple-glob"
-	"github.com/gopherlib/simple-glob/match"
+	"github.com/gopherlib/simple-glob/compiler"
 	"github.com/gopherlib/simple-glob/match/debug"
+	"github.com/gopherlib/simple-glob/syntax"
 )
 
 func main() {
@@ -34,12 +34,17 @@ func main() {
 		}
 	}
 
-	glob, err := glob.Compile(*pattern, separators...)
+	tree, err := syntax.Parse(*pattern)
+	if err != nil {
+		fmt.Println("could not parse pattern:", err)
+		os.Exit(1)
+	}
+
+	matcher, err := compiler.Compile(tree, separators)
 	if err != nil {
 		fmt.Println("could not compile pattern:", err)
 		os.Exit(1)
 	}
 
-	matcher := glob.(match.Matcher)
 	fmt.Fprint(os.Stdout, debug.Graphviz(*pattern, matcher))
 }
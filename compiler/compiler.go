@@ -2,10 +2,12 @@ package compiler
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/gopherlib/simple-glob/match"
 	"github.com/gopherlib/simple-glob/syntax/ast"
 	"github.com/gopherlib/simple-glob/util/runes"
+	sutil "github.com/gopherlib/simple-glob/util/strings"
 )
 
 func optimizeMatcher(matcher match.Matcher) match.Matcher {
@@ -30,18 +32,43 @@ func optimizeMatcher(matcher match.Matcher) match.Matcher {
 			rightNil = m.Right == nil
 		)
 		if leftNil && rightNil {
-			return match.NewText(r.Str)
+			return r
 		}
 
-		la, leftAny := m.Left.(match.Any)
-		ra, rightAny := m.Right.(match.Any)
+		ls, leftAny := anySeparators(m.Left)
+		rs, rightAny := anySeparators(m.Right)
+
+		switch {
+		case leftAny && rightAny && len(ls) == 0 && len(rs) == 0:
+			c := match.NewContains(r.Str)
+			c.Fold = r.Fold
+			return c
+
+		case rightNil:
+			if p, ok := m.Left.(match.PrefixAny); ok && len(p.Separators) == 0 && p.Fold == r.Fold {
+				ps := match.NewPrefixSuffix(p.Prefix, r.Str)
+				ps.Fold = r.Fold
+				return ps
+			}
+
+		case leftNil:
+			if sa, ok := m.Right.(match.SuffixAny); ok && len(sa.Separators) == 0 && sa.Fold == r.Fold {
+				ps := match.NewPrefixSuffix(r.Str, sa.Suffix)
+				ps.Fold = r.Fold
+				return ps
+			}
+		}
 
 		switch {
 		case rightNil && leftAny:
-			return match.NewSuffixAny(r.Str, la.Separators)
+			s := match.NewSuffixAny(r.Str, ls)
+			s.Fold = r.Fold
+			return s
 
 		case leftNil && rightAny:
-			return match.NewPrefixAny(r.Str, ra.Separators)
+			p := match.NewPrefixAny(r.Str, rs)
+			p.Fold = r.Fold
+			return p
 		}
 
 		return m
@@ -50,6 +77,19 @@ func optimizeMatcher(matcher match.Matcher) match.Matcher {
 	return matcher
 }
 
+// anySeparators returns separators of matcher that accepts any sequence of
+// characters except separators. Super is treated as Any without separators.
+func anySeparators(matcher match.Matcher) ([]rune, bool) {
+	switch m := matcher.(type) {
+	case match.Any:
+		return m.Separators, true
+	case match.Super:
+		return nil, true
+	default:
+		return nil, false
+	}
+}
+
 func compileMatchers(matchers []match.Matcher) (match.Matcher, error) {
 	if len(matchers) == 0 {
 		return nil, fmt.Errorf("compile error: need at least one matcher")
@@ -60,6 +100,9 @@ func compileMatchers(matchers []match.Matcher) (match.Matcher, error) {
 	if m := glueMatchers(matchers); m != nil {
 		return m, nil
 	}
+	if m := compileSubstrings(matchers); m != nil {
+		return m, nil
+	}
 
 	idx := -1
 	maxLen := -1
@@ -105,6 +148,44 @@ func compileMatchers(matchers []match.Matcher) (match.Matcher, error) {
 	return match.NewBTree(val, l, r), nil
 }
 
+// compileSubstrings compiles texts separated and surrounded by wildcards
+// matching any string, like `*a*b*`, into Contains or Substrings matcher.
+func compileSubstrings(matchers []match.Matcher) match.Matcher {
+	if len(matchers) < 3 || len(matchers)%2 == 0 {
+		return nil
+	}
+
+	var (
+		strs []string
+		fold bool
+	)
+	for i, matcher := range matchers {
+		if i%2 == 0 {
+			if sep, ok := anySeparators(matcher); !ok || len(sep) != 0 {
+				return nil
+			}
+			continue
+		}
+
+		t, ok := matcher.(match.Text)
+		if !ok || (len(strs) > 0 && t.Fold != fold) {
+			return nil
+		}
+		strs = append(strs, t.Str)
+		fold = t.Fold
+	}
+
+	if len(strs) == 1 {
+		c := match.NewContains(strs[0])
+		c.Fold = fold
+		return c
+	}
+
+	m := match.NewSubstrings(strs...)
+	m.Fold = fold
+	return m
+}
+
 func glueMatchers(matchers []match.Matcher) match.Matcher {
 	if m := glueMatchersAsEvery(matchers); m != nil {
 		return m
@@ -142,34 +223,42 @@ func glueMatchersAsEvery(matchers []match.Matcher) match.Matcher {
 
 	var (
 		hasAny    bool
+		hasSuper  bool
 		separator []rune
+		sepEqual  = true
 	)
 
-	for i, matcher := range matchers {
+	for _, matcher := range matchers {
 		var sep []rune
 
 		switch m := matcher.(type) {
+		case match.Super:
+			hasSuper = true
+			continue
+
 		case match.Any:
 			sep = m.Separators
-			hasAny = true
 
 		default:
 			return nil
 		}
 
 		// initialize
-		if i == 0 {
+		if !hasAny {
 			separator = sep
-		}
-
-		if runes.Equal(sep, separator) {
+			hasAny = true
 			continue
 		}
 
-		return nil
+		sepEqual = sepEqual && runes.Equal(sep, separator)
 	}
 
-	if hasAny {
+	switch {
+	// super matches everything any does, so any sequence of them is just super
+	case hasSuper:
+		return match.NewSuper()
+
+	case hasAny && sepEqual:
 		return match.NewAny(separator)
 	}
 
@@ -219,6 +308,171 @@ func minimizeMatchers(matchers []match.Matcher) []match.Matcher {
 	return minimizeMatchers(next)
 }
 
+func minimizeTree(tree *ast.Node) *ast.Node {
+	switch tree.Kind {
+	case ast.KindAnyOf:
+		return minimizeTreeAnyOf(tree)
+	default:
+		return nil
+	}
+}
+
+// minimizeTreeAnyOf tries to hoist common prefix and suffix out of alternatives,
+// so {ab,ac} becomes a{b,c}.
+func minimizeTreeAnyOf(tree *ast.Node) *ast.Node {
+	if !areOfSameKind(tree.Children, ast.KindPattern) {
+		return nil
+	}
+
+	children := splitCommonText(tree.Children)
+
+	commonLeft, commonRight := commonChildren(children)
+	commonLeftCount, commonRightCount := len(commonLeft), len(commonRight)
+	if commonLeftCount == 0 && commonRightCount == 0 { // there are no common parts
+		return nil
+	}
+
+	var result []*ast.Node
+	if commonLeftCount > 0 {
+		result = append(result, ast.NewNode(ast.KindPattern, nil, commonLeft...))
+	}
+
+	var anyOf []*ast.Node
+	for _, child := range children {
+		reuse := child.Children[commonLeftCount : len(child.Children)-commonRightCount]
+
+		var node *ast.Node
+		if len(reuse) == 0 {
+			// this pattern is completely reduced by commonLeft and commonRight patterns
+			// so it become nothing
+			node = ast.NewNode(ast.KindNothing, nil)
+		} else {
+			node = ast.NewNode(ast.KindPattern, nil, reuse...)
+		}
+		anyOf = appendIfUnique(anyOf, node)
+	}
+
+	switch {
+	case len(anyOf) == 1 && anyOf[0].Kind != ast.KindNothing:
+		result = append(result, anyOf[0])
+	case len(anyOf) > 1:
+		result = append(result, ast.NewNode(ast.KindAnyOf, nil, anyOf...))
+	}
+
+	if commonRightCount > 0 {
+		result = append(result, ast.NewNode(ast.KindPattern, nil, commonRight...))
+	}
+
+	return ast.NewNode(ast.KindPattern, nil, result...)
+}
+
+// splitCommonText returns copies of given patterns where leading and trailing
+// text nodes are split by the text common to all patterns. This lets
+// commonChildren find common parts of {api.prod,api.staging} alternatives.
+func splitCommonText(patterns []*ast.Node) []*ast.Node {
+	if len(patterns) <= 1 || sameLengthTexts(patterns) {
+		return patterns
+	}
+
+	var prefix, suffix string
+	for i, p := range patterns {
+		first, last, ok := edgeTexts(p)
+		if !ok {
+			return patterns
+		}
+		if i == 0 {
+			prefix, suffix = first, last
+			continue
+		}
+		prefix = sutil.CommonPrefix(prefix, first)
+		suffix = sutil.CommonSuffix(suffix, last)
+	}
+	if prefix == "" && suffix == "" {
+		return patterns
+	}
+
+	result := make([]*ast.Node, len(patterns))
+	for i, p := range patterns {
+		children := append([]*ast.Node{}, p.Children...)
+		children = splitFirstText(children, prefix)
+		children = splitLastText(children, suffix)
+		result[i] = ast.NewNode(ast.KindPattern, nil, children...)
+	}
+
+	return result
+}
+
+// sameLengthTexts reports whether patterns are texts of the same length.
+// Such alternatives are compiled to fixed length matcher, and splitting
+// them only produces more matchers.
+func sameLengthTexts(patterns []*ast.Node) bool {
+	length := -1
+	for _, p := range patterns {
+		if len(p.Children) != 1 || p.Children[0].Kind != ast.KindText {
+			return false
+		}
+		l := utf8.RuneCountInString(p.Children[0].Value.(ast.Text).Text)
+		if length != -1 && l != length {
+			return false
+		}
+		length = l
+	}
+	return true
+}
+
+func edgeTexts(pattern *ast.Node) (first, last string, ok bool) {
+	n := len(pattern.Children)
+	if n == 0 {
+		return "", "", false
+	}
+	f, l := pattern.Children[0], pattern.Children[n-1]
+	if f.Kind != ast.KindText || l.Kind != ast.KindText {
+		return "", "", false
+	}
+	return f.Value.(ast.Text).Text, l.Value.(ast.Text).Text, true
+}
+
+func splitFirstText(children []*ast.Node, prefix string) []*ast.Node {
+	text := children[0].Value.(ast.Text).Text
+	if len(prefix) == 0 || len(prefix) == len(text) {
+		return children
+	}
+	return append([]*ast.Node{
+		ast.NewNode(ast.KindText, ast.Text{Text: prefix}),
+		ast.NewNode(ast.KindText, ast.Text{Text: text[len(prefix):]}),
+	}, children[1:]...)
+}
+
+func splitLastText(children []*ast.Node, suffix string) []*ast.Node {
+	n := len(children)
+	text := children[n-1].Value.(ast.Text).Text
+	if len(suffix) == 0 || len(suffix) >= len(text) {
+		return children
+	}
+	return append(children[:n-1:n-1],
+		ast.NewNode(ast.KindText, ast.Text{Text: text[:len(text)-len(suffix)]}),
+		ast.NewNode(ast.KindText, ast.Text{Text: suffix}),
+	)
+}
+
+func areOfSameKind(nodes []*ast.Node, kind ast.Kind) bool {
+	for _, n := range nodes {
+		if n.Kind != kind {
+			return false
+		}
+	}
+	return true
+}
+
+func appendIfUnique(target []*ast.Node, val *ast.Node) []*ast.Node {
+	for _, n := range target {
+		if n.Equal(val) {
+			return target
+		}
+	}
+	return append(target, val)
+}
+
 func commonChildren(nodes []*ast.Node) (commonLeft, commonRight []*ast.Node) {
 	if len(nodes) <= 1 {
 		return
@@ -291,10 +545,10 @@ func leastChildren(nodes []*ast.Node) int {
 	return idx
 }
 
-func compileTreeChildren(tree *ast.Node, sep []rune) ([]match.Matcher, error) {
+func compileTreeChildren(tree *ast.Node, opts Options) ([]match.Matcher, error) {
 	var matchers []match.Matcher
 	for _, desc := range tree.Children {
-		m, err := compile(desc, sep)
+		m, err := compile(desc, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -303,14 +557,16 @@ func compileTreeChildren(tree *ast.Node, sep []rune) ([]match.Matcher, error) {
 	return matchers, nil
 }
 
-func compile(tree *ast.Node, sep []rune) (m match.Matcher, err error) {
+func compile(tree *ast.Node, opts Options) (m match.Matcher, err error) {
+	sep := opts.Separators
+
 	switch tree.Kind {
 
 	case ast.KindPattern:
 		if len(tree.Children) == 0 {
 			return match.NewNothing(), nil
 		}
-		matchers, err := compileTreeChildren(tree, sep)
+		matchers, err := compileTreeChildren(tree, opts)
 		if err != nil {
 			return nil, err
 		}
@@ -319,15 +575,80 @@ func compile(tree *ast.Node, sep []rune) (m match.Matcher, err error) {
 			return nil, err
 		}
 
+	case ast.KindAnyOf:
+		if n := minimizeTree(tree); n != nil {
+			m, err = compile(n, opts)
+			if err != nil {
+				return nil, err
+			}
+			if opts.Captures {
+				// keep minimized alternation reported as one capture
+				m = match.NewAnyOf(m)
+			}
+			break
+		}
+
+		if len(tree.Children) == 0 {
+			return match.NewNothing(), nil
+		}
+		matchers, err := compileTreeChildren(tree, opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(matchers) == 1 && !opts.Captures {
+			m = matchers[0]
+			break
+		}
+		m = match.NewAnyOf(matchers...)
+
 	case ast.KindAny:
 		m = match.NewAny(sep)
 
+	case ast.KindSuper:
+		m = match.NewSuper()
+
+	case ast.KindSingle:
+		m = match.NewSingle(sep)
+
+	case ast.KindList:
+		l := tree.Value.(ast.List)
+		chars := []rune(l.Chars)
+		if l.Not {
+			// separators are never matched by negated list
+			chars = append(chars, sep...)
+		}
+		list := match.NewList(chars, l.Not)
+		list.Fold = opts.CaseFold
+		m = list
+
+	case ast.KindRange:
+		r := tree.Value.(ast.Range)
+		rng := match.NewRange(r.Lo, r.Hi, r.Not, sep)
+		rng.Fold = opts.CaseFold
+		m = rng
+
+	case ast.KindClass:
+		items := make([]match.RuneMatcher, 0, len(tree.Children))
+		for _, desc := range tree.Children {
+			item, err := compile(desc, opts)
+			if err != nil {
+				return nil, err
+			}
+			r, ok := item.(match.RuneMatcher)
+			if !ok {
+				return nil, fmt.Errorf("could not compile tree: unexpected node in character class")
+			}
+			items = append(items, r)
+		}
+		m = match.NewClass(items, tree.Value.(ast.Class).Not, sep)
+
 	case ast.KindNothing:
 		m = match.NewNothing()
 
 	case ast.KindText:
-		t := tree.Value.(ast.Text)
-		m = match.NewText(t.Text)
+		t := match.NewText(tree.Value.(ast.Text).Text)
+		t.Fold = opts.CaseFold
+		m = t
 
 	default:
 		return nil, fmt.Errorf("could not compile tree: unknown node type")
@@ -336,11 +657,76 @@ func compile(tree *ast.Node, sep []rune) (m match.Matcher, err error) {
 	return optimizeMatcher(m), nil
 }
 
+// Backend selects how compiled matcher matches whole strings.
+type Backend int
+
+const (
+	// BackendAuto uses BackendDFA for patterns that could take super-linear
+	// time to match with BackendTree, and BackendTree otherwise.
+	BackendAuto Backend = iota
+	// BackendTree matches with a tree of matchers, backtracking if needed.
+	BackendTree
+	// BackendDFA matches and searches with lazily built deterministic automaton
+	// in linear time. Captures are still found by backtracking with BackendTree.
+	BackendDFA
+)
+
+// Options controls how the tree is compiled.
+type Options struct {
+	// Separators are characters that are not matched by `*`, `?` and negated classes.
+	Separators []rune
+	// CaseFold makes matchers compare characters under Unicode simple case folding.
+	CaseFold bool
+	// Backend selects how whole strings are matched.
+	Backend Backend
+	// Captures makes each alternation compile to a single AnyOf matcher,
+	// so match.Capture reports it as one capture. It keeps common parts of
+	// alternatives inside AnyOf, hiding them from the enclosing pattern,
+	// so matchers compiled with it should only be used for capturing.
+	Captures bool
+
+	// MaxWildcards limits the number of wildcards and character classes in the pattern.
+	// Zero means no limit.
+	MaxWildcards int
+	// MaxDepth limits the number of wildcards and character classes in a row
+	// in the pattern, counting only the deepest alternative of each alternation.
+	// It bounds the depth of BTree matchers the pattern is compiled to.
+	// Zero means no limit.
+	MaxDepth int
+	// MaxInputLength limits the length in bytes of strings the compiled matcher accepts.
+	// Longer strings are never matched. Zero means no limit.
+	MaxInputLength int
+}
+
+// Compile compiles the tree with the given separators.
 func Compile(tree *ast.Node, sep []rune) (match.Matcher, error) {
-	m, err := compile(tree, sep)
+	return CompileOptions(tree, Options{Separators: sep})
+}
+
+// CompileOptions compiles the tree with the given options.
+// It returns *LimitError if the tree exceeds a complexity limit.
+func CompileOptions(tree *ast.Node, opts Options) (match.Matcher, error) {
+	if n := countNodes(tree); opts.MaxWildcards > 0 && n > opts.MaxWildcards {
+		return nil, &LimitError{Limit: LimitWildcards, Value: n, Max: opts.MaxWildcards}
+	}
+	if d := patternDepth(tree); opts.MaxDepth > 0 && d > opts.MaxDepth {
+		return nil, &LimitError{Limit: LimitDepth, Value: d, Max: opts.MaxDepth}
+	}
+
+	m, err := compile(tree, opts)
 	if err != nil {
 		return nil, err
 	}
 
+	if opts.Backend == BackendDFA || (opts.Backend == BackendAuto && isRisky(tree, m)) {
+		if m, err = compileDFA(tree, opts, m); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.MaxInputLength > 0 {
+		m = match.NewLengthLimit(m, opts.MaxInputLength)
+	}
+
 	return m, nil
 }
@@ -156,6 +156,27 @@ func TestGlueMatchers(t *testing.T) {
 			},
 			match.NewAny([]rune{'a'}),
 		},
+		{
+			"any_super_any",
+			[]match.Matcher{
+				match.NewAny(separators),
+				match.NewSuper(),
+				match.NewAny(nil),
+			},
+			match.NewSuper(),
+		},
+		{
+			"any_any_different_separators",
+			[]match.Matcher{
+				match.NewAny(nil),
+				match.NewAny(separators),
+			},
+			match.NewBTree(
+				match.NewAny(nil),
+				nil,
+				match.NewAny(separators),
+			),
+		},
 	} {
 		act, err := compileMatchers(test.in)
 		if err != nil {
@@ -187,11 +208,29 @@ func TestCompileMatchers(t *testing.T) {
 				match.NewText("c"),
 				match.NewAny(nil),
 			},
+			match.NewContains("c"),
+		},
+		{
+			[]match.Matcher{
+				match.NewAny(separators),
+				match.NewText("c"),
+				match.NewAny(separators),
+			},
 			match.NewBTree(
 				match.NewText("c"),
+				match.NewAny(separators),
+				match.NewAny(separators),
+			),
+		},
+		{
+			[]match.Matcher{
 				match.NewAny(nil),
+				match.NewText("a"),
+				match.NewSuper(),
+				match.NewText("b"),
 				match.NewAny(nil),
-			),
+			},
+			match.NewSubstrings("a", "b"),
 		},
 		{
 			[]match.Matcher{
@@ -238,6 +277,19 @@ func TestConvertMatchers(t *testing.T) {
 				match.NewAny(nil),
 			},
 		},
+		{
+			[]match.Matcher{
+				match.NewText("c"),
+				match.NewAny(separators),
+				match.NewSuper(),
+				match.NewText("d"),
+			},
+			[]match.Matcher{
+				match.NewText("c"),
+				match.NewSuper(),
+				match.NewText("d"),
+			},
+		},
 	} {
 		act := minimizeMatchers(test.in)
 		if !reflect.DeepEqual(act, test.exp) {
@@ -253,6 +305,7 @@ func TestCompiler(t *testing.T) {
 		ast      *ast.Node
 		result   match.Matcher
 		sep      []rune
+		captures bool
 	}{
 		{
 			testName: "abc",
@@ -300,9 +353,19 @@ func TestCompiler(t *testing.T) {
 				ast.NewNode(ast.KindAny, nil),
 				ast.NewNode(ast.KindText, ast.Text{Text: "def"}),
 			),
+			result: match.NewPrefixSuffix("abc", "def"),
+		},
+		{
+			testName: "abc_any_def_separators",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindText, ast.Text{Text: "abc"}),
+				ast.NewNode(ast.KindAny, nil),
+				ast.NewNode(ast.KindText, ast.Text{Text: "def"}),
+			),
+			sep: separators,
 			result: match.NewBTree(
 				match.NewText("def"),
-				match.NewPrefixAny("abc", nil),
+				match.NewPrefixAny("abc", separators),
 				nil,
 			),
 		},
@@ -323,6 +386,166 @@ func TestCompiler(t *testing.T) {
 				match.NewAny(separators),
 			),
 		},
+		{
+			testName: "abc_single_def",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindText, ast.Text{Text: "abc"}),
+				ast.NewNode(ast.KindSingle, nil),
+				ast.NewNode(ast.KindText, ast.Text{Text: "def"}),
+			),
+			sep: separators,
+			result: match.NewRow(
+				7,
+				match.NewText("abc"),
+				match.NewSingle(separators),
+				match.NewText("def"),
+			),
+		},
+		{
+			testName: "any_single_abc",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAny, nil),
+				ast.NewNode(ast.KindSingle, nil),
+				ast.NewNode(ast.KindText, ast.Text{Text: "abc"}),
+			),
+			sep: separators,
+			result: match.NewBTree(
+				match.NewRow(
+					4,
+					match.NewSingle(separators),
+					match.NewText("abc"),
+				),
+				match.NewAny(separators),
+				nil,
+			),
+		},
+		{
+			testName: "abc_super",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindText, ast.Text{Text: "abc"}),
+				ast.NewNode(ast.KindAny, nil),
+				ast.NewNode(ast.KindSuper, nil),
+			),
+			sep:    separators,
+			result: match.NewPrefixAny("abc", nil),
+		},
+		{
+			testName: "super_abc",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindSuper, nil),
+				ast.NewNode(ast.KindText, ast.Text{Text: "abc"}),
+			),
+			sep:    separators,
+			result: match.NewSuffixAny("abc", nil),
+		},
+		{
+			testName: "not_list_range",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindList, ast.List{Chars: "xy", Not: true}),
+				ast.NewNode(ast.KindRange, ast.Range{Lo: 'a', Hi: 'z'}),
+			),
+			sep: separators,
+			result: match.NewRow(
+				2,
+				match.NewList([]rune{'x', 'y', '.'}, true),
+				match.NewRange('a', 'z', false, separators),
+			),
+		},
+		{
+			testName: "any_of_common_prefix",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAnyOf, nil,
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.prod"}),
+					),
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.staging"}),
+					),
+				),
+			),
+			result: match.NewBTree(
+				match.NewText("api."),
+				nil,
+				match.NewAnyOf(
+					match.NewText("prod"),
+					match.NewText("staging"),
+				),
+			),
+		},
+		{
+			testName: "any_of_common_prefix_captures",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAnyOf, nil,
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.prod"}),
+					),
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.staging"}),
+					),
+				),
+			),
+			captures: true,
+			result: match.NewAnyOf(
+				match.NewBTree(
+					match.NewText("api."),
+					nil,
+					match.NewAnyOf(
+						match.NewText("prod"),
+						match.NewText("staging"),
+					),
+				),
+			),
+		},
+		{
+			testName: "any_of_common_children",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAnyOf, nil,
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindAny, nil),
+						ast.NewNode(ast.KindText, ast.Text{Text: ".jpg"}),
+					),
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindAny, nil),
+						ast.NewNode(ast.KindText, ast.Text{Text: "-png"}),
+					),
+				),
+			),
+			sep: separators,
+			result: match.NewBTree(
+				match.NewAnyOf(
+					match.NewText(".jpg"),
+					match.NewText("-png"),
+				),
+				match.NewAny(separators),
+				nil,
+			),
+		},
+		{
+			testName: "any_of_common_suffix_text",
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAnyOf, nil,
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.prod"}),
+					),
+					ast.NewNode(ast.KindPattern, nil,
+						ast.NewNode(ast.KindText, ast.Text{Text: "api.staging"}),
+					),
+				),
+				ast.NewNode(ast.KindText, ast.Text{Text: ".example.com"}),
+			),
+			result: match.NewBTree(
+				match.NewText(".example.com"),
+				match.NewBTree(
+					match.NewText("api."),
+					nil,
+					match.NewAnyOf(
+						match.NewText("prod"),
+						match.NewText("staging"),
+					),
+				),
+				nil,
+			),
+		},
 		{
 			testName: "abc3",
 			ast: ast.NewNode(ast.KindPattern, nil,
@@ -395,7 +618,7 @@ func TestCompiler(t *testing.T) {
 		},
 	} {
 		t.Run(test.testName, func(t *testing.T) {
-			m, err := Compile(test.ast, test.sep)
+			m, err := CompileOptions(test.ast, Options{Separators: test.sep, Captures: test.captures, Backend: BackendTree})
 			if err != nil {
 				t.Errorf("compilation error: %s", err)
 			}
@@ -406,3 +629,84 @@ func TestCompiler(t *testing.T) {
 		})
 	}
 }
+
+func TestCompileBackend(t *testing.T) {
+	any := ast.NewNode(ast.KindAny, nil)
+	single := ast.NewNode(ast.KindSingle, nil)
+	text := func(s string) *ast.Node {
+		return ast.NewNode(ast.KindText, ast.Text{Text: s})
+	}
+
+	for id, test := range []struct {
+		ast     *ast.Node
+		sep     []rune
+		backend Backend
+		dfa     bool
+	}{
+		{
+			ast:     ast.NewNode(ast.KindPattern, nil, single, text("a"), any),
+			backend: BackendAuto,
+			dfa:     false,
+		},
+		{
+			// variable length left part of the tree
+			ast:     ast.NewNode(ast.KindPattern, nil, any, text("a"), any),
+			backend: BackendAuto,
+			dfa:     true,
+		},
+		{
+			ast:     ast.NewNode(ast.KindPattern, nil, any, text("a"), any),
+			backend: BackendTree,
+			dfa:     false,
+		},
+		{
+			ast:     ast.NewNode(ast.KindPattern, nil, any, text("a"), any, text("b"), any),
+			backend: BackendAuto,
+			dfa:     true,
+		},
+		{
+			ast:     ast.NewNode(ast.KindPattern, nil, any, text("a"), any, text("b"), any),
+			backend: BackendTree,
+			dfa:     false,
+		},
+		{
+			ast:     ast.NewNode(ast.KindPattern, nil, text("abc")),
+			backend: BackendDFA,
+			dfa:     true,
+		},
+		{
+			ast: ast.NewNode(ast.KindPattern, nil, any, text("a"), single, any, text("a"), any, text("b")),
+			sep: []rune{},
+			dfa: true,
+		},
+		{
+			ast: ast.NewNode(ast.KindPattern, nil,
+				ast.NewNode(ast.KindAnyOf, nil,
+					ast.NewNode(ast.KindPattern, nil, any, text("a"), any, text("a"), any, text("a"), any, text("b")),
+					ast.NewNode(ast.KindPattern, nil, text("x")),
+				),
+			),
+			sep: []rune{},
+			dfa: true,
+		},
+		{
+			// dedicated matcher does not backtrack
+			ast: ast.NewNode(ast.KindPattern, nil, any, text("a"), any, text("b"), any, text("c"), any),
+			sep: []rune{},
+			dfa: false,
+		},
+	} {
+		sep := separators
+		if test.sep != nil {
+			sep = test.sep
+		}
+		m, err := CompileOptions(test.ast, Options{Separators: sep, Backend: test.backend})
+		if err != nil {
+			t.Errorf("#%d compile error: %s", id, err)
+			continue
+		}
+		if _, ok := m.(match.DFA); ok != test.dfa {
+			t.Errorf("#%d unexpected matcher: %s", id, m)
+		}
+	}
+}
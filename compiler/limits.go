@@ -0,0 +1,97 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/gopherlib/simple-glob/syntax/ast"
+)
+
+// Limit is a kind of pattern complexity limit.
+type Limit int
+
+const (
+	// LimitPatternLength limits the pattern length in bytes.
+	LimitPatternLength Limit = iota + 1
+	// LimitWildcards limits the number of wildcards and character classes in the pattern.
+	LimitWildcards
+	// LimitDepth limits the number of wildcards and character classes in a row
+	// in the pattern, counting only the deepest alternative of each alternation.
+	// It bounds the depth of BTree matchers the pattern is compiled to.
+	LimitDepth
+)
+
+func (l Limit) String() string {
+	switch l {
+	case LimitPatternLength:
+		return "pattern length"
+	case LimitWildcards:
+		return "wildcard count"
+	case LimitDepth:
+		return "pattern depth"
+	default:
+		return fmt.Sprintf("limit(%d)", int(l))
+	}
+}
+
+// LimitError is returned when a pattern exceeds a complexity limit.
+type LimitError struct {
+	Limit Limit
+	// Value is the actual value of the limited quantity.
+	Value int
+	// Max is the maximum allowed value.
+	Max int
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %d exceeds limit of %d", e.Limit, e.Value, e.Max)
+}
+
+// CheckPatternLength returns LimitError if pattern is longer than max bytes. Zero max means no limit.
+func CheckPatternLength(pattern string, max int) error {
+	if max > 0 && len(pattern) > max {
+		return &LimitError{Limit: LimitPatternLength, Value: len(pattern), Max: max}
+	}
+	return nil
+}
+
+// countNodes returns the number of wildcards and character classes in the tree.
+func countNodes(tree *ast.Node) int {
+	var n int
+	switch tree.Kind {
+	case ast.KindAny, ast.KindSuper, ast.KindSingle, ast.KindList, ast.KindRange:
+		n++
+	case ast.KindClass:
+		// items of the class are not counted separately
+		return 1
+	}
+	for _, c := range tree.Children {
+		n += countNodes(c)
+	}
+	return n
+}
+
+// patternDepth returns the number of wildcards and character classes in a row
+// in the tree, where each alternation counts as its deepest alternative.
+// The tree is compiled to BTree matchers nested up to that depth.
+func patternDepth(tree *ast.Node) int {
+	switch tree.Kind {
+	case ast.KindPattern:
+		var d int
+		for _, c := range tree.Children {
+			d += patternDepth(c)
+		}
+		return d
+	case ast.KindAnyOf:
+		var d int
+		for _, c := range tree.Children {
+			if cd := patternDepth(c); cd > d {
+				d = cd
+			}
+		}
+		return d
+	case ast.KindAny, ast.KindSuper, ast.KindSingle, ast.KindList, ast.KindRange, ast.KindClass:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,180 @@
+package compiler
+
+import (
+	"fmt"
+
+	"github.com/gopherlib/simple-glob/match"
+	"github.com/gopherlib/simple-glob/syntax/ast"
+)
+
+// riskyWildcards is the number of variable length wildcards in a row in a pattern
+// from which backtracking of BTree could take super-linear time.
+const riskyWildcards = 3
+
+// isRisky reports whether matching or searching with m compiled from the tree
+// could take super-linear time. Trees with variable length left part are risky
+// for searching, as their Index tries every start offset.
+func isRisky(tree *ast.Node, m match.Matcher) bool {
+	many := countWildcards(tree) >= riskyWildcards
+	return anyTree(m, func(t match.BTree) bool {
+		return many || (t.Left != nil && t.LeftLengthRunes == -1)
+	})
+}
+
+// countWildcards returns the number of variable length wildcards in a row
+// in the tree, where each alternation counts as its alternative with most of them.
+func countWildcards(tree *ast.Node) int {
+	switch tree.Kind {
+	case ast.KindPattern:
+		var n int
+		for _, c := range tree.Children {
+			n += countWildcards(c)
+		}
+		return n
+	case ast.KindAnyOf:
+		var n int
+		for _, c := range tree.Children {
+			if cn := countWildcards(c); cn > n {
+				n = cn
+			}
+		}
+		return n
+	case ast.KindAny, ast.KindSuper:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// anyTree reports whether f returns true for some BTree in m.
+func anyTree(m match.Matcher, f func(match.BTree) bool) bool {
+	switch m := m.(type) {
+	case match.BTree:
+		return f(m) || anyTree(m.Value, f) ||
+			(m.Left != nil && anyTree(m.Left, f)) ||
+			(m.Right != nil && anyTree(m.Right, f))
+	case match.AnyOf:
+		for _, c := range m.Matchers {
+			if anyTree(c, f) {
+				return true
+			}
+		}
+	case match.Row:
+		for _, c := range m.Matchers {
+			if anyTree(c, f) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// compileDFA compiles the tree into DFA matcher that uses m as a fallback for Capture.
+func compileDFA(tree *ast.Node, opts Options, m match.Matcher) (match.Matcher, error) {
+	nfa := match.NewNFA()
+	start, err := compileNFA(nfa, tree, opts, nfa.Match(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	// reversed strings of the pattern followed by anything,
+	// so Index could find the leftmost match start in one pass
+	reverse, err := compileNFA(nfa, tree, opts, nfa.Match(), true)
+	if err != nil {
+		return nil, err
+	}
+	reverse = nfa.Star(match.NewSuper(), reverse)
+
+	return match.NewDFA(nfa, start, reverse, m), nil
+}
+
+// compileNFA adds states matching the tree followed by the next state and returns the first of them.
+// If reverse is set, the states match reversed strings matched by the tree.
+func compileNFA(nfa *match.NFA, tree *ast.Node, opts Options, next int, reverse bool) (int, error) {
+	sep := opts.Separators
+
+	switch tree.Kind {
+	case ast.KindPattern:
+		for i := range tree.Children {
+			c := tree.Children[len(tree.Children)-1-i]
+			if reverse {
+				c = tree.Children[i]
+			}
+			var err error
+			if next, err = compileNFA(nfa, c, opts, next, reverse); err != nil {
+				return 0, err
+			}
+		}
+		return next, nil
+
+	case ast.KindAnyOf:
+		if len(tree.Children) == 0 {
+			return next, nil
+		}
+		var start int
+		for i, c := range tree.Children {
+			s, err := compileNFA(nfa, c, opts, next, reverse)
+			if err != nil {
+				return 0, err
+			}
+			if i == 0 {
+				start = s
+			} else {
+				start = nfa.Split(start, s)
+			}
+		}
+		return start, nil
+
+	case ast.KindText:
+		chars := []rune(tree.Value.(ast.Text).Text)
+		for i := range chars {
+			j := len(chars) - 1 - i
+			if reverse {
+				j = i
+			}
+			l := match.NewList(chars[j:j+1], false)
+			l.Fold = opts.CaseFold
+			next = nfa.Rune(l, next)
+		}
+		return next, nil
+
+	case ast.KindAny:
+		return nfa.Star(match.NewSingle(sep), next), nil
+
+	case ast.KindSuper:
+		return nfa.Star(match.NewSuper(), next), nil
+
+	case ast.KindSingle:
+		return nfa.Rune(match.NewSingle(sep), next), nil
+
+	case ast.KindList:
+		l := tree.Value.(ast.List)
+		chars := []rune(l.Chars)
+		if l.Not {
+			// separators are never matched by negated list
+			chars = append(chars, sep...)
+		}
+		list := match.NewList(chars, l.Not)
+		list.Fold = opts.CaseFold
+		return nfa.Rune(list, next), nil
+
+	case ast.KindRange:
+		r := tree.Value.(ast.Range)
+		rng := match.NewRange(r.Lo, r.Hi, r.Not, sep)
+		rng.Fold = opts.CaseFold
+		return nfa.Rune(rng, next), nil
+
+	case ast.KindClass:
+		class, err := compile(tree, opts)
+		if err != nil {
+			return 0, err
+		}
+		return nfa.Rune(class.(match.RuneMatcher), next), nil
+
+	case ast.KindNothing:
+		return next, nil
+
+	default:
+		return 0, fmt.Errorf("could not compile tree: unknown node type")
+	}
+}
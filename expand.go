@@ -0,0 +1,115 @@
+package glob
+
+import (
+	"strings"
+)
+
+func (g glob) Expand(template, s string) (string, bool) {
+	captures, ok := g.Captures(s)
+	if !ok {
+		return "", false
+	}
+
+	var buf strings.Builder
+	expand(&buf, template, s, captures)
+
+	return buf.String(), true
+}
+
+func (g glob) ReplaceAllString(src, template string) string {
+	locs := g.FindAllStringIndex(src, -1)
+	if locs == nil {
+		return src
+	}
+
+	var (
+		buf  strings.Builder
+		last int
+	)
+	for _, loc := range locs {
+		buf.WriteString(src[last:loc[0]])
+
+		m := src[loc[0]:loc[1]]
+		if captures, ok := g.Captures(m); ok {
+			expand(&buf, template, m, captures)
+		} else {
+			// should not happen, as the match is found by the same pattern,
+			// but keeping it is better than expanding missing captures
+			buf.WriteString(m)
+		}
+
+		last = loc[1]
+	}
+	buf.WriteString(src[last:])
+
+	return buf.String()
+}
+
+// expand writes template to buf, replacing references by captures.
+func expand(buf *strings.Builder, template, s string, captures []string) {
+	for len(template) > 0 {
+		i := strings.IndexAny(template, "${")
+		if i == -1 {
+			buf.WriteString(template)
+			return
+		}
+		buf.WriteString(template[:i])
+		template = template[i:]
+
+		if strings.HasPrefix(template, "$$") || strings.HasPrefix(template, "{{") {
+			buf.WriteByte(template[0])
+			template = template[2:]
+			continue
+		}
+
+		n, rest, ok := extractReference(template)
+		if !ok {
+			// not a reference, so write the character as is
+			buf.WriteByte(template[0])
+			template = template[1:]
+			continue
+		}
+		template = rest
+
+		switch {
+		case n == 0:
+			buf.WriteString(s)
+		case n <= len(captures):
+			buf.WriteString(captures[n-1])
+		}
+	}
+}
+
+// extractReference parses reference in form of $1, ${1} or {1} at the beginning of template.
+func extractReference(template string) (n int, rest string, ok bool) {
+	var closed bool
+	switch {
+	case strings.HasPrefix(template, "${"):
+		template, closed = template[2:], true
+	case template[0] == '{':
+		template, closed = template[1:], true
+	default:
+		template = template[1:]
+	}
+
+	var i int
+	for ; i < len(template) && '0' <= template[i] && template[i] <= '9'; i++ {
+		if n >= 1e8 {
+			// too big index
+			return 0, "", false
+		}
+		n = n*10 + int(template[i]-'0')
+	}
+	if i == 0 {
+		return 0, "", false
+	}
+
+	if closed {
+		if i == len(template) || template[i] != '}' {
+			return 0, "", false
+		}
+		i++
+	}
+
+	return n, template[i:], true
+}
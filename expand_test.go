@@ -0,0 +1,75 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/gopherlib/simple-glob/match"
+)
+
+func TestExpand(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		template   string
+		fixture    string
+		result     string
+		ok         bool
+	}{
+		{pattern: "*.jpeg", template: "$1.jpg", fixture: "cat.jpeg", result: "cat.jpg", ok: true},
+		{pattern: "*.jpeg", template: "{1}.jpg", fixture: "cat.jpeg", result: "cat.jpg", ok: true},
+		{pattern: "*.jpeg", template: "${1}_small.jpg", fixture: "cat.jpeg", result: "cat_small.jpg", ok: true},
+		{pattern: "*.jpeg", template: "$1.jpg", fixture: "cat.png", result: "", ok: false},
+		{pattern: "*.*.example.com", delimiters: []rune{'.'}, template: "$2-$1.example.net", fixture: "api.eu.example.com", result: "eu-api.example.net", ok: true},
+		{pattern: "logs/*/*", delimiters: []rune{'/'}, template: "archive/$1/$2 ($0)", fixture: "logs/2024/app.log", result: "archive/2024/app.log (logs/2024/app.log)", ok: true},
+		{pattern: "*", template: "$$1 costs $1", fixture: "5", result: "$1 costs 5", ok: true},
+		{pattern: "*", template: "$3{2}${x}{x}$", fixture: "a", result: "${x}{x}$", ok: true},
+		{pattern: "*", template: "${1", fixture: "a", result: "${1", ok: true},
+		{pattern: "*", template: "{{1}={1}", fixture: "a", result: "{1}=a", ok: true},
+		{pattern: "*", template: "{{{1}}}", fixture: "a", result: "{a}}", ok: true},
+		{pattern: "*.{jpg,jpeg}", features: Alternates, template: "$1.$2.bak", fixture: "cat.jpeg", result: "cat.jpeg.bak", ok: true},
+		{pattern: "img-???", features: Single, template: "$3$2$1", fixture: "img-abc", result: "cba", ok: true},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+
+			result, ok := g.Expand(test.template, test.fixture)
+			if result != test.result || ok != test.ok {
+				t.Errorf("Expand(%q, %q) = %q, %v; want %q, %v\n%s", test.template, test.fixture, result, ok, test.result, test.ok, g)
+			}
+		})
+	}
+}
+
+func TestReplaceAllString(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		delimiters []rune
+		template   string
+		src        string
+		result     string
+	}{
+		{pattern: "*.jpeg", delimiters: []rune{' '}, template: "$1.jpg", src: "cat.jpeg and dog.jpeg", result: "cat.jpg and dog.jpg"},
+		{pattern: "*.jpeg", delimiters: []rune{' '}, template: "$1.jpg", src: "nothing here", result: "nothing here"},
+		{pattern: "id=*", delimiters: []rune{';'}, template: "id=<$1>", src: "id=1;id=2", result: "id=<1>;id=<2>"},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			g := MustCompile(test.pattern, test.delimiters...)
+
+			if result := g.ReplaceAllString(test.src, test.template); result != test.result {
+				t.Errorf("ReplaceAllString(%q, %q) = %q; want %q\n%s", test.src, test.template, result, test.result, g)
+			}
+		})
+	}
+}
+
+func TestReplaceAllStringCapturesFailed(t *testing.T) {
+	// matcher reporting no captures at all for the found matches
+	g := glob{
+		matcher:  MustCompile("*.jpeg", ' ').(glob).matcher,
+		capturer: match.NewNothing(),
+	}
+	if result := g.ReplaceAllString("cat.jpeg and dog.jpeg", "$1.jpg"); result != "cat.jpeg and dog.jpeg" {
+		t.Errorf("ReplaceAllString() = %q; want matches kept unchanged", result)
+	}
+}
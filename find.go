@@ -0,0 +1,96 @@
+package glob
+
+import (
+	"unicode/utf8"
+
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+// find returns location of the leftmost match in s[pos:], relative to s.
+func (g glob) find(s string, pos int) (start, end int) {
+	index, segments := g.matcher.Index(s[pos:])
+	if index == -1 {
+		return -1, -1
+	}
+
+	// segments are sorted from the shortest match to the longest one
+	length := segments[len(segments)-1]
+	if g.shortest {
+		length = segments[0]
+	}
+
+	start = pos + index
+	return start, start + length
+}
+
+func (g glob) FindStringIndex(s string) []int {
+	start, end := g.find(s, 0)
+	if start == -1 {
+		return nil
+	}
+	return []int{start, end}
+}
+
+func (g glob) FindAllStringIndex(s string, n int) [][]int {
+	if n < 0 {
+		n = len(s) + 1
+	}
+
+	var (
+		result  [][]int
+		prevEnd = -1
+	)
+	for pos := 0; pos <= len(s) && len(result) < n; {
+		start, end := g.find(s, pos)
+		if start == -1 {
+			break
+		}
+
+		// empty match right after the previous match is ignored, as regexp does
+		if end > start || start != prevEnd {
+			result = append(result, []int{start, end})
+		}
+		prevEnd = end
+
+		if end > start {
+			pos = end
+			continue
+		}
+		if start == len(s) {
+			break
+		}
+		_, w := utf8.DecodeRuneInString(s[start:])
+		pos = start + w
+	}
+
+	return result
+}
+
+func (g glob) FindIndex(b []byte) []int {
+	return g.FindStringIndex(sutil.FromBytes(b))
+}
+
+func (g glob) FindAllIndex(b []byte, n int) [][]int {
+	return g.FindAllStringIndex(sutil.FromBytes(b), n)
+}
+
+func (g glob) FindString(s string) string {
+	start, end := g.find(s, 0)
+	if start == -1 {
+		return ""
+	}
+	return s[start:end]
+}
+
+func (g glob) FindAllString(s string, n int) []string {
+	locs := g.FindAllStringIndex(s, n)
+	if locs == nil {
+		return nil
+	}
+
+	result := make([]string, len(locs))
+	for i, loc := range locs {
+		result[i] = s[loc[0]:loc[1]]
+	}
+	return result
+}
@@ -0,0 +1,139 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindStringIndex(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		shortest   bool
+		delimiters []rune
+		fixture    string
+		loc        []int
+	}{
+		{pattern: "abc", fixture: "xxabcxx", loc: []int{2, 5}},
+		{pattern: "abc", fixture: "xxabxx", loc: nil},
+		{pattern: "*.github.com", fixture: "see api.github.com for details", delimiters: []rune{'.', ' '}, loc: []int{4, 18}},
+		{pattern: "*.github.com", fixture: "see api.github.com for details", delimiters: []rune{'.'}, loc: []int{0, 18}},
+		{pattern: "a*c", fixture: "xabcbc", loc: []int{1, 6}},
+		{pattern: "a*c", fixture: "xabcbc", shortest: true, loc: []int{1, 4}},
+		{pattern: "id=?", fixture: "user id=7;", features: Single, loc: []int{5, 9}},
+		{pattern: "[0-9]*", fixture: "abc 123 def", delimiters: []rune{' '}, features: Classes, loc: []int{4, 7}},
+		{pattern: "{cat,dog}s", fixture: "hotdogs and cats", features: Alternates, loc: []int{3, 7}},
+		{pattern: "*", fixture: "abc", loc: []int{0, 3}},
+		{pattern: "*", fixture: "abc", shortest: true, loc: []int{0, 0}},
+		{pattern: "日*語", fixture: "x日本語x", loc: []int{1, 10}},
+		{pattern: "*?*", fixture: "a\xffb", features: Single, loc: []int{0, 3}},
+		{pattern: "a*", fixture: "xa\xff", loc: []int{1, 3}},
+		{pattern: "*[!a]*", fixture: "a\xff", features: Classes, loc: []int{0, 2}},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			opts := []Option{WithFeatures(test.features), WithSeparators(test.delimiters...)}
+			if test.shortest {
+				opts = append(opts, WithShortest())
+			}
+			g := MustCompileOptions(test.pattern, opts...)
+
+			loc := g.FindStringIndex(test.fixture)
+			if !reflect.DeepEqual(loc, test.loc) {
+				t.Errorf("FindStringIndex(%q) = %v; want %v\n%s", test.fixture, loc, test.loc, g)
+			}
+
+			if act := g.FindIndex([]byte(test.fixture)); !reflect.DeepEqual(act, loc) {
+				t.Errorf("FindIndex(%q) = %v; FindStringIndex = %v\n%s", test.fixture, act, loc, g)
+			}
+
+			exp := bruteForceFind(g, test.fixture, test.shortest)
+			if !reflect.DeepEqual(loc, exp) {
+				t.Errorf("FindStringIndex(%q) = %v; brute force found %v\n%s", test.fixture, loc, exp, g)
+			}
+		})
+	}
+}
+
+func TestFindAllStringIndex(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		fixture    string
+		n          int
+		locs       [][]int
+	}{
+		{
+			pattern: "ab", fixture: "ab ab ab", n: -1,
+			locs: [][]int{{0, 2}, {3, 5}, {6, 8}},
+		},
+		{
+			pattern: "ab", fixture: "ab ab ab", n: 2,
+			locs: [][]int{{0, 2}, {3, 5}},
+		},
+		{
+			pattern: "ab", fixture: "ab ab ab", n: 0,
+			locs: nil,
+		},
+		{
+			pattern: "*.com", fixture: "a.com b.com", delimiters: []rune{' '}, n: -1,
+			locs: [][]int{{0, 5}, {6, 11}},
+		},
+		{
+			pattern: "*", fixture: "ab.cd", delimiters: []rune{'.'}, n: -1,
+			locs: [][]int{{0, 2}, {3, 5}},
+		},
+		{
+			pattern: "x", fixture: "abc", n: -1,
+			locs: nil,
+		},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+
+			locs := g.FindAllStringIndex(test.fixture, test.n)
+			if !reflect.DeepEqual(locs, test.locs) {
+				t.Errorf("FindAllStringIndex(%q, %d) = %v; want %v\n%s", test.fixture, test.n, locs, test.locs, g)
+			}
+			if act := g.FindAllIndex([]byte(test.fixture), test.n); !reflect.DeepEqual(act, locs) {
+				t.Errorf("FindAllIndex(%q, %d) = %v; FindAllStringIndex = %v\n%s", test.fixture, test.n, act, locs, g)
+			}
+		})
+	}
+}
+
+func TestFindString(t *testing.T) {
+	g := MustCompileFeatures("*.{jpg,png}", Alternates, ' ')
+
+	if act, exp := g.FindString("see cat.png and dog.jpg"), "cat.png"; act != exp {
+		t.Errorf("FindString() = %q; want %q", act, exp)
+	}
+	if act, exp := g.FindAllString("see cat.png and dog.jpg", -1), []string{"cat.png", "dog.jpg"}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("FindAllString() = %q; want %q", act, exp)
+	}
+	if act := g.FindAllString("nothing here", -1); act != nil {
+		t.Errorf("FindAllString() = %q; want nil", act)
+	}
+}
+
+// bruteForceFind returns location of the leftmost match of g in s
+// by checking all substrings of s.
+func bruteForceFind(g Glob, s string, shortest bool) []int {
+	// the trailing space makes range visit len(s) as a start too
+	for start := range s + " " {
+		var loc []int
+		for i := range s[start:] + " " {
+			end := start + i
+			if g.Match(s[start:end]) {
+				loc = []int{start, end}
+				if shortest {
+					break
+				}
+			}
+		}
+		if loc != nil {
+			return loc
+		}
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+
+	"github.com/gopherlib/simple-glob/match"
+)
+
+// GlobFS calls fn for each file or directory of fsys whose path matches the pattern.
+// The pattern is compiled with the given options and `/` as a separator, and is
+// matched against slash-separated paths as used by io/fs, like "dir/file.go".
+//
+// Directories that could not contain matching paths, as reported by
+// Glob.CouldMatchPrefix, are not read. Paths are visited in
+// lexical order, as in fs.WalkDir. If fn returns fs.SkipDir for a directory,
+// its contents are skipped; any other non-nil error stops the walk and is returned.
+func GlobFS(fsys fs.FS, pattern string, fn func(name string, d fs.DirEntry) error, opts ...Option) error {
+	opts = append(opts[:len(opts):len(opts)], WithSeparators('/'))
+	g, err := CompileOptions(pattern, opts...)
+	if err != nil {
+		return err
+	}
+	m := g.(glob).matcher
+	root := walkRoot(m)
+
+	return fs.WalkDir(fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == root && errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+
+		if m.Match(name) {
+			if err := fn(name, d); err != nil {
+				return err
+			}
+		}
+
+		if d.IsDir() && !m.CouldMatchPrefix(name+"/") {
+			return fs.SkipDir
+		}
+		return nil
+	})
+}
+
+// walkRoot returns the deepest directory named in the literal prefix of m,
+// so paths matching m could be searched starting from it.
+func walkRoot(m match.Matcher) string {
+	prefix, _ := literalPrefix(m)
+	if i := strings.LastIndexByte(prefix, '/'); i > 0 && fs.ValidPath(prefix[:i]) {
+		return prefix[:i]
+	}
+	return "."
+}
@@ -0,0 +1,184 @@
+package glob
+
+import (
+	"errors"
+	"io/fs"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+var testFS = fstest.MapFS{
+	"go.mod":                    {},
+	"main.go":                   {},
+	"README.md":                 {},
+	"cmd/tool/main.go":          {},
+	"cmd/tool/main_test.go":     {},
+	"pkg/api/api.go":            {},
+	"pkg/api/api_test.go":       {},
+	"pkg/api/v1/types.go":       {},
+	"pkg/apis/apis.go":          {},
+	"pkg/util/strings.go":       {},
+	"vendor/lib/lib.go":         {},
+	"vendor/lib/internal/x.go":  {},
+	"docs/images/logo.png":      {},
+	"docs/images/日本/banner.jpg": {},
+}
+
+// openFS records names of opened files.
+type openFS struct {
+	fsys   fs.FS
+	opened []string
+}
+
+func (o *openFS) Open(name string) (fs.File, error) {
+	o.opened = append(o.opened, name)
+	return o.fsys.Open(name)
+}
+
+func TestGlobFS(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		features Feature
+		matches  []string
+		opened   []string
+	}{
+		{
+			pattern: "*.go",
+			matches: []string{"main.go"},
+		},
+		{
+			pattern: "*/*/*.go",
+			matches: []string{"cmd/tool/main.go", "cmd/tool/main_test.go", "pkg/api/api.go", "pkg/api/api_test.go", "pkg/apis/apis.go", "pkg/util/strings.go", "vendor/lib/lib.go"},
+		},
+		{
+			pattern: "pkg/api/*.go",
+			matches: []string{"pkg/api/api.go", "pkg/api/api_test.go"},
+			opened:  []string{"pkg/api", "pkg/api"},
+		},
+		{
+			pattern: "pkg/api*/*.go",
+			matches: []string{"pkg/api/api.go", "pkg/api/api_test.go", "pkg/apis/apis.go"},
+			opened:  []string{"pkg", "pkg", "pkg/api", "pkg/apis"},
+		},
+		{
+			pattern:  "pkg/**_test.go",
+			features: Super,
+			matches:  []string{"pkg/api/api_test.go"},
+		},
+		{
+			pattern:  "**/*.go",
+			features: Super,
+			matches:  []string{"cmd/tool/main.go", "cmd/tool/main_test.go", "pkg/api/api.go", "pkg/api/api_test.go", "pkg/api/v1/types.go", "pkg/apis/apis.go", "pkg/util/strings.go", "vendor/lib/internal/x.go", "vendor/lib/lib.go"},
+		},
+		{
+			pattern:  "docs/images/{*.png,日本/*}",
+			features: Alternates,
+			matches:  []string{"docs/images/logo.png", "docs/images/日本/banner.jpg"},
+			opened:   []string{"docs/images", "docs/images", "docs/images/日本"},
+		},
+		{
+			pattern:  "PKG/API/*.GO",
+			features: CaseFold,
+			matches:  []string{"pkg/api/api.go", "pkg/api/api_test.go"},
+		},
+		{
+			pattern: "pkg/*",
+			matches: []string{"pkg/api", "pkg/apis", "pkg/util"},
+		},
+		{
+			pattern: "missing/*.go",
+			matches: nil,
+			opened:  []string{"missing"},
+		},
+	} {
+		t.Run(test.pattern, func(t *testing.T) {
+			fsys := &openFS{fsys: testFS}
+
+			var matches []string
+			err := GlobFS(fsys, test.pattern, func(name string, d fs.DirEntry) error {
+				matches = append(matches, name)
+				return nil
+			}, WithFeatures(test.features))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !sort.StringsAreSorted(matches) {
+				t.Errorf("matches are not sorted: %v", matches)
+			}
+			if !reflect.DeepEqual(matches, test.matches) {
+				t.Errorf("unexpected matches: exp: %v, act: %v", test.matches, matches)
+			}
+			if test.opened != nil && !reflect.DeepEqual(fsys.opened, test.opened) {
+				t.Errorf("unexpected opened: exp: %v, act: %v", test.opened, fsys.opened)
+			}
+
+			if exp := bruteForceGlobFS(t, test.pattern, test.features); !reflect.DeepEqual(matches, exp) {
+				t.Errorf("matches differ from brute force: exp: %v, act: %v", exp, matches)
+			}
+		})
+	}
+}
+
+func bruteForceGlobFS(t *testing.T, pattern string, features Feature) (matches []string) {
+	g := MustCompileFeatures(pattern, features, '/')
+	err := fs.WalkDir(testFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name != "." && g.Match(name) {
+			matches = append(matches, name)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestGlobFSStop(t *testing.T) {
+	stop := errors.New("stop")
+
+	var matches []string
+	err := GlobFS(testFS, "**.go", func(name string, d fs.DirEntry) error {
+		matches = append(matches, name)
+		if len(matches) == 2 {
+			return stop
+		}
+		return nil
+	}, WithFeatures(Super))
+	if err != stop {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(matches) != 2 {
+		t.Errorf("unexpected matches: %v", matches)
+	}
+}
+
+func TestGlobFSSkipDir(t *testing.T) {
+	var matches []string
+	err := GlobFS(testFS, "**", func(name string, d fs.DirEntry) error {
+		matches = append(matches, name)
+		if d.IsDir() && name != "docs" {
+			return fs.SkipDir
+		}
+		return nil
+	}, WithFeatures(Super))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	exp := []string{"README.md", "cmd", "docs", "docs/images", "go.mod", "main.go", "pkg", "vendor"}
+	if !reflect.DeepEqual(matches, exp) {
+		t.Errorf("unexpected matches: exp: %v, act: %v", exp, matches)
+	}
+}
+
+func TestGlobFSError(t *testing.T) {
+	if err := GlobFS(testFS, "[a", func(string, fs.DirEntry) error { return nil }, WithFeatures(Classes)); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
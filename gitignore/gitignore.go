@@ -0,0 +1,360 @@
+// Package gitignore implements matching of paths against .gitignore files.
+//
+// Patterns are compiled with glob package using `/` as a separator, so `*`,
+// `?` and character classes never match `/`, while `**` in leading `**/`,
+// trailing `/**` and inner `/**/` matches any number of directories.
+package gitignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob"
+)
+
+// FileName is the name of ignore files read by Load.
+const FileName = ".gitignore"
+
+const features = glob.Single | glob.Escape | glob.Super | glob.Classes
+
+// Pattern is a single pattern of an ignore file.
+type Pattern struct {
+	// Line is the 1-based number of the line the pattern was given on.
+	Line int
+	// Negate reports whether the pattern was given as `!pattern`,
+	// so matching paths are not ignored.
+	Negate bool
+	// DirOnly reports whether the pattern ends with `/`,
+	// so it matches only directories.
+	DirOnly bool
+
+	text  string
+	globs []glob.Glob
+}
+
+func (p Pattern) String() string {
+	return p.text
+}
+
+// Match reports whether the pattern matches the path relative to the directory of the ignore file.
+func (p Pattern) Match(path string, isDir bool) bool {
+	if p.DirOnly && !isDir {
+		return false
+	}
+	for _, g := range p.globs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePattern parses a single line of an ignore file.
+// It returns false if the line is empty or a comment.
+// Positions in *glob.SyntaxError refer to the line itself.
+func ParsePattern(line string) (Pattern, bool, error) {
+	line = strings.TrimSuffix(line, "\r")
+	text := trimTrailingSpaces(line)
+	if text == "" || text[0] == '#' {
+		return Pattern{}, false, nil
+	}
+
+	p := Pattern{text: text}
+	if text[0] == '!' {
+		p.Negate = true
+		text = text[1:]
+	}
+	if strings.HasSuffix(text, "/") {
+		p.DirOnly = true
+		text = strings.TrimRight(text, "/")
+	}
+	if text == "" {
+		return Pattern{}, false, fmt.Errorf("gitignore: empty pattern %q", line)
+	}
+
+	// pattern without a slash matches at any level below the ignore file,
+	// otherwise it is relative to the ignore file directory
+	anchored := strings.Contains(text, "/")
+	text = strings.TrimPrefix(text, "/")
+
+	segments := strings.Split(text, "/")
+	for i, s := range segments {
+		if s != "**" {
+			segments[i] = collapseStars(s)
+		}
+	}
+	if !anchored && segments[0] != "**" {
+		segments = append([]string{"**"}, segments...)
+	}
+
+	for _, variant := range expandSuper(segments) {
+		g, err := glob.CompileFeatures(variant, features, '/')
+		if err != nil {
+			return Pattern{}, false, fmt.Errorf("gitignore: invalid pattern %q: %w", line, p.syntaxError(err))
+		}
+		p.globs = append(p.globs, g)
+	}
+
+	return p, true, nil
+}
+
+// syntaxError returns err found in a pattern rewritten from the line of p
+// with its position moved to the line.
+func (p Pattern) syntaxError(err error) error {
+	var se *glob.SyntaxError
+	if !errors.As(err, &se) {
+		return err
+	}
+
+	// the pattern as given on the line has the same problem
+	// as patterns it is rewritten to
+	text := strings.TrimRight(p.text, "/")
+	var skip int
+	if p.Negate {
+		skip = 1
+	}
+	var orig *glob.SyntaxError
+	if _, err = glob.CompileFeatures(text[skip:], features, '/'); !errors.As(err, &orig) {
+		return se
+	}
+
+	e := *orig
+	e.Pattern = p.text
+	e.Offset += skip
+	e.Column = utf8.RuneCountInString(e.Pattern[:e.Offset]) + 1
+	return &e
+}
+
+// expandSuper returns glob patterns for given path segments.
+// Each `**` segment followed by another segment also matches no directories
+// at all, so it gives one more pattern without it.
+func expandSuper(segments []string) []string {
+	head := segments[0]
+	if len(segments) == 1 {
+		return []string{head}
+	}
+
+	var result []string
+	for _, tail := range expandSuper(segments[1:]) {
+		result = append(result, head+"/"+tail)
+		if head == "**" {
+			result = append(result, tail)
+		}
+	}
+	return result
+}
+
+// collapseStars replaces consecutive unescaped `*` with single one,
+// because `**` not delimited by slashes is the same as `*` in ignore files.
+func collapseStars(s string) string {
+	if !strings.Contains(s, "**") {
+		return s
+	}
+
+	var (
+		b    strings.Builder
+		star bool
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			b.WriteByte(c)
+			i++
+			c = s[i]
+			star = false
+		case c == '*':
+			if star {
+				continue
+			}
+			star = true
+		default:
+			star = false
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// trimTrailingSpaces removes trailing spaces unless they are escaped with `\`.
+func trimTrailingSpaces(s string) string {
+	i := len(s)
+	for i > 0 && s[i-1] == ' ' {
+		if i > 1 && s[i-2] == '\\' {
+			break
+		}
+		i--
+	}
+	return s[:i]
+}
+
+// File is a parsed ignore file.
+type File struct {
+	// Dir is the slash-separated directory of the ignore file, relative to
+	// the root. It is empty for the root directory.
+	Dir      string
+	Patterns []Pattern
+	// Warnings holds errors of lines that are not valid patterns.
+	Warnings []error
+}
+
+// Parse reads the ignore file located in the dir directory from r.
+// Like git, it skips lines that are not valid patterns; errors of
+// such lines are reported in Warnings of the file.
+func Parse(r io.Reader, dir string) (*File, error) {
+	f := &File{Dir: cleanPath(dir)}
+
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		p, ok, err := ParsePattern(s.Text())
+		if err != nil {
+			f.Warnings = append(f.Warnings, fmt.Errorf("%s:%d: %w", path.Join(f.Dir, FileName), line, err))
+			continue
+		}
+		if ok {
+			p.Line = line
+			f.Patterns = append(f.Patterns, p)
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// decide returns the last pattern of the file that matches the path relative
+// to the root. It returns false if the path is outside of the file directory
+// or no pattern matches.
+func (f *File) decide(name string, isDir bool) (Pattern, bool) {
+	if f.Dir != "" {
+		if !strings.HasPrefix(name, f.Dir+"/") {
+			return Pattern{}, false
+		}
+		name = name[len(f.Dir)+1:]
+	}
+	for i := len(f.Patterns) - 1; i >= 0; i-- {
+		if f.Patterns[i].Match(name, isDir) {
+			return f.Patterns[i], true
+		}
+	}
+	return Pattern{}, false
+}
+
+// Matcher reports whether paths are ignored by a set of possibly nested ignore files.
+type Matcher struct {
+	files []*File
+}
+
+// New creates Matcher for the given ignore files.
+func New(files ...*File) *Matcher {
+	m := &Matcher{}
+	for _, f := range files {
+		m.Add(f)
+	}
+	return m
+}
+
+// Add adds the ignore file to the matcher.
+// Patterns of files in deeper directories take precedence.
+func (m *Matcher) Add(f *File) {
+	depth := dirDepth(f.Dir)
+
+	i := len(m.files)
+	for i > 0 && dirDepth(m.files[i-1].Dir) > depth {
+		i--
+	}
+	m.files = append(m.files, nil)
+	copy(m.files[i+1:], m.files[i:])
+	m.files[i] = f
+}
+
+// Warnings returns warnings of all ignore files of the matcher.
+func (m *Matcher) Warnings() []error {
+	var warnings []error
+	for _, f := range m.files {
+		warnings = append(warnings, f.Warnings...)
+	}
+	return warnings
+}
+
+// Ignored reports whether the slash-separated path relative to the root is ignored.
+// A path inside of an ignored directory is ignored as well.
+func (m *Matcher) Ignored(name string, isDir bool) bool {
+	name = cleanPath(name)
+	if name == "" {
+		return false
+	}
+
+	for i := 0; i < len(name); i++ {
+		if name[i] == '/' && m.ignored(name[:i], true) {
+			return true
+		}
+	}
+	return m.ignored(name, isDir)
+}
+
+func (m *Matcher) ignored(name string, isDir bool) bool {
+	for i := len(m.files) - 1; i >= 0; i-- {
+		if p, ok := m.files[i].decide(name, isDir); ok {
+			return !p.Negate
+		}
+	}
+	return false
+}
+
+// Load reads all ignore files of fsys that are not inside of ignored directories.
+// Lines that are not valid patterns are skipped and reported by Matcher.Warnings.
+func Load(fsys fs.FS) (*Matcher, error) {
+	m := New()
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if name != "." && m.Ignored(name, true) {
+			return fs.SkipDir
+		}
+
+		file, err := fsys.Open(path.Join(name, FileName))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		defer file.Close()
+
+		f, err := Parse(file, name)
+		if err != nil {
+			return err
+		}
+		m.Add(f)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func cleanPath(dir string) string {
+	return strings.Trim(path.Clean("/"+dir), "/")
+}
+
+func dirDepth(dir string) int {
+	if dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
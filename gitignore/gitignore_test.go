@@ -0,0 +1,246 @@
+package gitignore
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/gopherlib/simple-glob"
+)
+
+func TestParsePattern(t *testing.T) {
+	for _, test := range []struct {
+		line    string
+		ok      bool
+		negate  bool
+		dirOnly bool
+		text    string
+	}{
+		{line: "", ok: false},
+		{line: "# comment", ok: false},
+		{line: "   ", ok: false},
+		{line: `\#hash`, ok: true, text: `\#hash`},
+		{line: "foo", ok: true, text: "foo"},
+		{line: "foo   ", ok: true, text: "foo"},
+		{line: `foo\ `, ok: true, text: `foo\ `},
+		{line: "foo\r", ok: true, text: "foo"},
+		{line: "!foo", ok: true, negate: true, text: "!foo"},
+		{line: "build/", ok: true, dirOnly: true, text: "build/"},
+		{line: "*.[0-9a-f]", ok: true, text: "*.[0-9a-f]"},
+		{line: "[a-zA-Z]_[a-]", ok: true, text: "[a-zA-Z]_[a-]"},
+		{line: "!core.[!a-z0-9]", ok: true, negate: true, text: "!core.[!a-z0-9]"},
+	} {
+		p, ok, err := ParsePattern(test.line)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %s", test.line, err)
+			continue
+		}
+		if ok != test.ok || p.Negate != test.negate || p.DirOnly != test.dirOnly || p.String() != test.text {
+			t.Errorf(
+				"%q: unexpected pattern: exp: %q %v negate=%v dir=%v, act: %q %v negate=%v dir=%v",
+				test.line, test.text, test.ok, test.negate, test.dirOnly, p.String(), ok, p.Negate, p.DirOnly,
+			)
+		}
+	}
+}
+
+func TestParsePatternError(t *testing.T) {
+	for _, test := range []struct {
+		line   string
+		column int
+	}{
+		{line: "!"},
+		{line: "/"},
+		{line: "[a", column: 1},
+		{line: `foo\`, column: 4},
+		{line: "!foo[", column: 5},
+		{line: "!/a/[b/", column: 5},
+	} {
+		_, _, err := ParsePattern(test.line)
+		if err == nil {
+			t.Errorf("%q: expected error", test.line)
+			continue
+		}
+		var se *glob.SyntaxError
+		if errors.As(err, &se) != (test.column != 0) {
+			t.Errorf("%q: unexpected error: %s", test.line, err)
+			continue
+		}
+		if se != nil && (se.Column != test.column || se.Pattern != test.line) {
+			t.Errorf("%q: unexpected error position: exp: column %d, act: column %d of %q", test.line, test.column, se.Column, se.Pattern)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	f, err := Parse(strings.NewReader("*.o\n/\nfoo[\n!\nbar\\\n!*.c\n"), "src")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	var act []int
+	for _, p := range f.Patterns {
+		act = append(act, p.Line)
+	}
+	if exp := []int{1, 6}; !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected lines of patterns: exp: %v, act: %v", exp, act)
+	}
+	if len(f.Warnings) != 4 {
+		t.Errorf("unexpected warnings: %q", f.Warnings)
+	}
+}
+
+func TestIgnored(t *testing.T) {
+	root := strings.Join([]string{
+		"# build artifacts",
+		"*.o",
+		"/bin",
+		"build/",
+		"doc/*.txt",
+		"**/logs",
+		"tmp/**",
+		"a/**/z",
+		"foo\\ ",
+		"*.log",
+		"!keep.log",
+		"\\!important",
+		"[Cc]ache",
+		"x**y",
+		"*.[1-9ab]~",
+		"tmp[a-z0-9-]",
+	}, "\n")
+
+	m := New()
+	for _, f := range []struct {
+		dir, content string
+	}{
+		{"", root},
+		{"sub", "*.txt\n!*.o\n"},
+	} {
+		file, err := Parse(strings.NewReader(f.content), f.dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		m.Add(file)
+	}
+
+	for _, test := range []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "main.o", ignored: true},
+		{path: "src/main.o", ignored: true},
+		{path: "main.c", ignored: false},
+
+		{path: "bin", isDir: true, ignored: true},
+		{path: "bin/tool", ignored: true},
+		{path: "src/bin", isDir: true, ignored: false},
+
+		{path: "build", isDir: true, ignored: true},
+		{path: "build", isDir: false, ignored: false},
+		{path: "src/build", isDir: true, ignored: true},
+		{path: "src/build/out.bin", ignored: true},
+
+		{path: "doc/readme.txt", ignored: true},
+		{path: "doc/api/readme.txt", ignored: false},
+		{path: "src/doc/readme.txt", ignored: false},
+
+		{path: "logs", isDir: true, ignored: true},
+		{path: "src/logs/today", ignored: true},
+
+		{path: "tmp", isDir: true, ignored: false},
+		{path: "tmp/x", ignored: true},
+		{path: "tmp/x/y", ignored: true},
+
+		{path: "a/z", ignored: true},
+		{path: "a/b/c/z", ignored: true},
+		{path: "b/a/z", ignored: false},
+
+		{path: "foo ", ignored: true},
+		{path: "foo", ignored: false},
+
+		{path: "app.log", ignored: true},
+		{path: "keep.log", ignored: false},
+		{path: "src/keep.log", ignored: false},
+		{path: "!important", ignored: true},
+
+		{path: "cache", isDir: true, ignored: true},
+		{path: "Cache", isDir: true, ignored: true},
+
+		{path: "xay", ignored: true},
+		{path: "xa/by", ignored: false},
+
+		{path: "obj.b~", ignored: true},
+		{path: "src/obj.7~", ignored: true},
+		{path: "obj.c~", ignored: false},
+		{path: "tmp-", ignored: true},
+		{path: "tmp9", ignored: true},
+		{path: "tmpX", ignored: false},
+
+		// nested ignore file
+		{path: "sub/notes.txt", ignored: true},
+		{path: "notes.txt", ignored: false},
+		{path: "sub/main.o", ignored: false},
+		{path: "sub/deep/main.o", ignored: false},
+		{path: "sub/app.log", ignored: true},
+
+		{path: "./main.o", ignored: true},
+		{path: "", isDir: true, ignored: false},
+	} {
+		if act := m.Ignored(test.path, test.isDir); act != test.ignored {
+			t.Errorf("Ignored(%q, %v) = %v; want %v", test.path, test.isDir, act, test.ignored)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore":         {Data: []byte("*.log\nvendor/\n")},
+		"app.log":            {},
+		"main.go":            {},
+		"pkg/.gitignore":     {Data: []byte("!debug.log\ngen/\n")},
+		"pkg/debug.log":      {},
+		"pkg/gen/x.go":       {},
+		"vendor/.gitignore":  {Data: []byte("[invalid\n")},
+		"vendor/lib/lib.go":  {},
+		"other/gen/y.go":     {},
+		"other/.gitignore":   {Data: []byte("# nothing\n*.[0-9a-f]\n[invalid\n")},
+		"other/core.a":       {},
+		"other/core.z":       {},
+		"other/trace.log":    {},
+		"other/deep/a.log":   {},
+		"other/deep/main.go": {},
+	}
+
+	m, err := Load(fsys)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if w := m.Warnings(); len(w) != 1 || !strings.HasPrefix(w[0].Error(), "other/.gitignore:3: ") {
+		t.Errorf("unexpected warnings: %q", w)
+	}
+
+	for _, test := range []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "app.log", ignored: true},
+		{path: "main.go", ignored: false},
+		{path: "pkg/debug.log", ignored: false},
+		{path: "pkg/gen/x.go", ignored: true},
+		{path: "vendor/lib/lib.go", ignored: true},
+		{path: "other/gen/y.go", ignored: false},
+		{path: "other/trace.log", ignored: true},
+		{path: "other/deep/a.log", ignored: true},
+		{path: "other/deep/main.go", ignored: false},
+		{path: "other/core.a", ignored: true},
+		{path: "other/core.z", ignored: false},
+	} {
+		if act := m.Ignored(test.path, test.isDir); act != test.ignored {
+			t.Errorf("Ignored(%q, %v) = %v; want %v", test.path, test.isDir, act, test.ignored)
+		}
+	}
+}
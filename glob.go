@@ -2,12 +2,153 @@ package glob
 
 import (
 	"github.com/gopherlib/simple-glob/compiler"
+	"github.com/gopherlib/simple-glob/match"
 	"github.com/gopherlib/simple-glob/syntax"
+	"github.com/gopherlib/simple-glob/syntax/lexer"
 )
 
-// Glob represents compiled glob pattern.
+// Glob is a compiled pattern. It also implements match.Matcher.
+// Methods are added to Glob as the package grows, so it is not meant
+// to be implemented outside of this package.
 type Glob interface {
+	// Match reports whether the whole string matches the pattern.
 	Match(string) bool
+
+	// MatchBytes reports whether the whole b matches the pattern.
+	// It does not copy b into a string, so it does not allocate.
+	MatchBytes(b []byte) bool
+
+	// CouldMatchPrefix reports whether some string beginning with s could match
+	// the pattern, so whole subtrees of paths or ranges of sorted keys beginning
+	// with s could be skipped when it returns false. It may return true for s
+	// no matching string begins with, but never returns false for one that does.
+	CouldMatchPrefix(s string) bool
+
+	// FindStringIndex returns a two-element slice of integers defining the location
+	// of the leftmost match of the pattern in s. The match itself is at s[loc[0]:loc[1]].
+	// A return value of nil indicates no match.
+	FindStringIndex(s string) (loc []int)
+
+	// FindAllStringIndex returns a slice of locations of all successive non-overlapping
+	// matches of the pattern in s. If n >= 0, it returns at most n matches.
+	// A return value of nil indicates no match.
+	FindAllStringIndex(s string, n int) [][]int
+
+	// FindIndex is the same as FindStringIndex for b, without copying it into a string.
+	FindIndex(b []byte) (loc []int)
+
+	// FindAllIndex is the same as FindAllStringIndex for b, without copying it into a string.
+	FindAllIndex(b []byte, n int) [][]int
+
+	// FindString returns the text of the leftmost match of the pattern in s.
+	// It returns an empty string if there is no match or the match is empty.
+	FindString(s string) string
+
+	// FindAllString returns a slice of texts of all successive non-overlapping
+	// matches of the pattern in s. If n >= 0, it returns at most n matches.
+	FindAllString(s string, n int) []string
+
+	// LiteralPrefix returns a literal string that every matching string begins with.
+	// It returns true if the pattern matches only the literal string itself.
+	// Case-insensitive patterns have no literal prefix.
+	LiteralPrefix() (prefix string, complete bool)
+
+	// RequiredLiterals returns literal strings that every matching string
+	// contains, in the order they appear in the pattern. Literals that only some
+	// of alternatives contain and literals of case-insensitive patterns are not reported.
+	RequiredLiterals() []string
+
+	// Captures reports whether the whole string matches the pattern and returns
+	// texts matched by each wildcard, in pattern order. See Compile for details.
+	Captures(s string) ([]string, bool)
+
+	// Expand matches the whole string s and returns the template with
+	// references to captures replaced by the captured text. It returns false
+	// if s does not match.
+	//
+	// In the template, $1, ${1} and {1} denote the text matched by the first
+	// wildcard, $0 denotes the whole string, $$ denotes a literal $ and {{
+	// denotes a literal {, so {{1} is expanded to {1}.
+	// References to missing captures are replaced by an empty string.
+	Expand(template, s string) (string, bool)
+
+	// ReplaceAllString returns a copy of src, replacing matches of the pattern
+	// with the template expanded against each match. See Expand for the template syntax.
+	ReplaceAllString(src, template string) string
+}
+
+type glob struct {
+	matcher match.Matcher
+	// capturer is used for Captures instead of matcher if it is not nil.
+	capturer match.Matcher
+	shortest bool
+}
+
+func (g glob) Match(s string) bool {
+	return g.matcher.Match(s)
+}
+
+func (g glob) MatchBytes(b []byte) bool {
+	return match.MatchBytes(g.matcher, b)
+}
+
+func (g glob) CouldMatchPrefix(s string) bool {
+	return g.matcher.CouldMatchPrefix(s)
+}
+
+func (g glob) String() string {
+	return g.matcher.String()
+}
+
+// Index implements match.Matcher, so compiled globs could still be used
+// as matchers, as Compile used to return the matcher itself.
+func (g glob) Index(s string) (int, []int) {
+	return g.matcher.Index(s)
+}
+
+// Len implements match.Matcher.
+func (g glob) Len() int {
+	return g.matcher.Len()
+}
+
+// Feature is a set of optional pattern syntax extensions and behaviors.
+// Features are disabled by default, so their special characters are matched literally.
+type Feature uint
+
+const (
+	// Single enables `?` to match any single non-separator character.
+	Single Feature = 1 << iota
+	// Escape enables `\` to escape the following character, so it is matched literally.
+	Escape
+	// Super enables `**` to match any sequence of characters including separators.
+	Super
+	// Classes enables `[...]` character classes: `[abc]`, `[a-z]`, `[a-z0-9_]` and negated `[!abc]`, `[^a-z]`.
+	Classes
+	// Alternates enables `{a,b,c}` to match any of the comma-separated alternatives.
+	Alternates
+	// CaseFold makes the pattern match case-insensitively, comparing characters
+	// under Unicode simple case folding. Character classes match a character
+	// if any of its case variants is within the class.
+	CaseFold
+)
+
+func (f Feature) lexerMode() (mode lexer.Mode) {
+	if f&Single != 0 {
+		mode |= lexer.ModeSingle
+	}
+	if f&Escape != 0 {
+		mode |= lexer.ModeEscape
+	}
+	if f&Super != 0 {
+		mode |= lexer.ModeSuper
+	}
+	if f&Classes != 0 {
+		mode |= lexer.ModeClasses
+	}
+	if f&Alternates != 0 {
+		mode |= lexer.ModeAlternates
+	}
+	return mode
 }
 
 // Compile creates Glob for given pattern and strings (if any present after pattern) as separators.
@@ -18,26 +159,140 @@ type Glob interface {
 //
 //	term:
 //	    `*`         matches any sequence of non-separator characters
+//	    `**`        matches any sequence of characters (with Super feature)
+//	    `?`         matches any single non-separator character (with Single feature)
+//	    `\` c       matches character c (with Escape feature)
+//	    `[` [ `!` | `^` ] { c | lo `-` hi } `]`
+//	                matches any character from the list or c for lo <= c <= hi
+//	                of any of the ranges, as `[a-z0-9_]` (with Classes feature)
+//	    `{` pattern-list `}`
+//	                matches any of the comma-separated patterns (with Alternates feature)
+//
+// Negated classes never match separators. A `-` that is the first or
+// the last character of the class, or follows a range, is matched literally.
+//
+// Each `*`, `**`, `?`, character class and alternation gives one capture
+// reported by Glob.Captures. Adjacent `*` wildcards are merged into a single
+// capture, and wildcards inside alternatives are not captured separately.
+// When a string can be matched in several ways, earlier parts of the
+// pattern consume as much text as possible.
+//
+// Patterns with many wildcards are matched and searched by an automaton
+// in linear time, but captures are always found by backtracking, so Captures,
+// Expand and ReplaceAllString could take super-linear time for them.
 func Compile(pattern string, separators ...rune) (Glob, error) {
-	ast, err := syntax.Parse(pattern)
+	return CompileFeatures(pattern, 0, separators...)
+}
+
+// SyntaxError is returned when the pattern could not be parsed.
+// It holds the position of the problem in the pattern.
+type SyntaxError = syntax.SyntaxError
+
+// CompileFeatures is the same as Compile, except that it enables the given syntax features.
+func CompileFeatures(pattern string, features Feature, separators ...rune) (Glob, error) {
+	return CompileOptions(pattern, WithFeatures(features), WithSeparators(separators...))
+}
+
+// CompileOptions creates Glob for given pattern configured by the given options.
+// Without options it is the same as Compile without separators.
+func CompileOptions(pattern string, opts ...Option) (Glob, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ast, err := o.parse(pattern)
 	if err != nil {
 		return nil, err
 	}
 
-	matcher, err := compiler.Compile(ast, separators)
+	copts := compiler.Options{
+		Separators:     o.separators,
+		CaseFold:       o.features&CaseFold != 0,
+		MaxWildcards:   o.maxWildcards,
+		MaxDepth:       o.maxDepth,
+		MaxInputLength: o.maxInputLength,
+	}
+	matcher, err := compiler.CompileOptions(ast, copts)
 	if err != nil {
 		return nil, err
 	}
 
-	return matcher, nil
+	var capturer match.Matcher
+	if hasAlternation(ast) {
+		// alternations are compiled separately for Captures, so capturing
+		// them as a whole does not keep Match from using factored matchers
+		copts.Captures = true
+		copts.Backend = compiler.BackendTree
+		if capturer, err = compiler.CompileOptions(ast, copts); err != nil {
+			return nil, err
+		}
+	}
+
+	return glob{
+		matcher:  matcher,
+		capturer: capturer,
+		shortest: o.shortest,
+	}, nil
 }
 
 // MustCompile is the same as Compile, except that if Compile returns error, this will panic
 func MustCompile(pattern string, separators ...rune) Glob {
-	g, err := Compile(pattern, separators...)
+	return MustCompileFeatures(pattern, 0, separators...)
+}
+
+// MustCompileFeatures is the same as CompileFeatures, except that if CompileFeatures returns error, this will panic
+func MustCompileFeatures(pattern string, features Feature, separators ...rune) Glob {
+	g, err := CompileFeatures(pattern, features, separators...)
 	if err != nil {
 		panic(err)
 	}
 
 	return g
 }
+
+// MustCompileOptions is the same as CompileOptions, except that if CompileOptions returns error, this will panic
+func MustCompileOptions(pattern string, opts ...Option) Glob {
+	g, err := CompileOptions(pattern, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return g
+}
+
+// QuoteMeta returns a string that quotes all glob pattern meta characters
+// inside the argument text. For example, QuoteMeta(`*.example.com`) returns `\*.example.com`.
+// The result must be compiled with Escape feature enabled, otherwise the added
+// backslashes are matched literally. Characters are quoted whether or not the
+// features they are special with are enabled; see QuoteMetaFeatures.
+func QuoteMeta(s string) string {
+	return quoteMeta(s, syntax.Special)
+}
+
+// QuoteMetaFeatures is the same as QuoteMeta, except that it quotes only characters
+// special with the given features, so QuoteMetaFeatures("a,b?", Escape) returns "a,b?".
+// The result must be compiled with the given features and Escape.
+func QuoteMetaFeatures(s string, features Feature) string {
+	mode := (features | Escape).lexerMode()
+	return quoteMeta(s, func(c byte) bool {
+		return syntax.SpecialMode(c, mode)
+	})
+}
+
+func quoteMeta(s string, special func(byte) bool) string {
+	b := make([]byte, 2*len(s))
+
+	// a byte loop is correct because all meta characters are ASCII
+	j := 0
+	for i := 0; i < len(s); i++ {
+		if special(s[i]) {
+			b[j] = '\\'
+			j++
+		}
+		b[j] = s[i]
+		j++
+	}
+
+	return string(b[0:j])
+}
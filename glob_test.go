@@ -1,7 +1,15 @@
 package glob
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"testing"
+
+	"github.com/gopherlib/simple-glob/compiler"
+	"github.com/gopherlib/simple-glob/match"
+	"github.com/gopherlib/simple-glob/syntax"
+	"github.com/gopherlib/simple-glob/syntax/lexer"
 )
 
 type test struct {
@@ -9,68 +17,217 @@ type test struct {
 	match      string
 	should     bool
 	delimiters []rune
+	features   Feature
+}
+
+// globTests is shared by tests of all glob features.
+var globTests = []test{
+	{should: true, pattern: "* cat * eyes", match: "my cat has very bright eyes"},
+	{should: true, pattern: "", match: ""},
+	{should: true, pattern: `a*b`, match: "a*b"},
+	{should: false, pattern: `a\*b`, match: "a*b"},
+	{should: true, pattern: `a\*b`, match: `a\*b`},
+
+	{should: true, pattern: "*ä", match: "åä"},
+	{should: true, pattern: "abc", match: "abc"},
+	{should: true, pattern: "a*c", match: "abc"},
+	{should: true, pattern: "a*c", match: "a12345c"},
+	{should: false, pattern: "a?c", match: "a1c"},
+	{should: true, pattern: "a.*", match: "a.b.c"},
+	{should: true, pattern: "a.b", match: "a.b", delimiters: []rune{'.'}},
+	{should: true, pattern: "a.*", match: "a.b", delimiters: []rune{'.'}},
+	{should: false, pattern: "a.**", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "a.?.c", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: true, pattern: "a.*.c", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "a.?.?", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "a.*", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "?at", match: "cat"},
+	{should: true, pattern: "ca*", match: "ca*t"},
+	{should: true, pattern: "ca*", match: "ca*"},
+	{should: true, pattern: "c*t", match: "ca*aaaaaaaat"},
+	{should: false, pattern: "?at", match: "fat"},
+	{should: true, pattern: "*", match: "abc"},
+	{should: false, pattern: `\*`, match: "*"},
+	{should: false, pattern: "**", match: "a.b.c", delimiters: []rune{'.'}},
+
+	{should: false, pattern: "?at", match: "at"},
+	{should: false, pattern: "?at", match: "fat", delimiters: []rune{'f'}},
+	{should: false, pattern: "*at", match: "fat", delimiters: []rune{'f'}},
+	{should: false, pattern: "a.*", match: "a.b.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "a.?.c", match: "a.bb.c", delimiters: []rune{'.'}},
+	{should: true, pattern: "a.*.c", match: "a.bb.c", delimiters: []rune{'.'}},
+	{should: false, pattern: "*", match: "a.b.c", delimiters: []rune{'.'}},
+
+	{should: true, pattern: "*test", match: "this is a test"},
+	{should: true, pattern: "this*", match: "this is a test"},
+	{should: true, pattern: "*is *", match: "this is a test"},
+	{should: true, pattern: "*is*a*", match: "this is a test"},
+	{should: true, pattern: "**test**", match: "this is a test"},
+	{should: true, pattern: "**is**a***test*", match: "this is a test"},
+
+	{should: false, pattern: "*is", match: "this is a test"},
+	{should: false, pattern: "*no*", match: "this is a test"},
+	{should: true, pattern: "*abc", match: "abcabc"},
+	{should: true, pattern: "/*", match: "/rate"},
+
+	{should: true, pattern: "*//*.example.com", match: "https://www.example.com"},
+	{should: true, pattern: "*//*.example.com", match: "https://www.example.com", delimiters: []rune{'.'}},
+	{should: true, pattern: "*//*example.com", match: "https://www.example.com"},
+	{should: false, pattern: "*//*example.com", match: "https://www.example.com", delimiters: []rune{'.'}},
+	{should: false, pattern: "*//*.example.com", match: "http://example.com"},
+	{should: false, pattern: "*//*.example.com", match: "http://example.com.net"},
+	{should: true, pattern: "*//*example.com", match: "http://example.com"},
+
+	{should: true, pattern: "a?c", match: "a1c", features: Single},
+	{should: true, pattern: "a?c", match: "a?c", features: Single},
+	{should: false, pattern: "a?c", match: "ac", features: Single},
+	{should: false, pattern: "a?c", match: "a12c", features: Single},
+	{should: true, pattern: "?at", match: "cat", features: Single},
+	{should: true, pattern: "?at", match: "日at", features: Single},
+	{should: false, pattern: "?at", match: "at", features: Single},
+	{should: false, pattern: "?at", match: "fat", delimiters: []rune{'f'}, features: Single},
+	{should: true, pattern: "a.?.c", match: "a.b.c", delimiters: []rune{'.'}, features: Single},
+	{should: false, pattern: "a.?.c", match: "a.bb.c", delimiters: []rune{'.'}, features: Single},
+	{should: false, pattern: "a?c", match: "a.c", delimiters: []rune{'.'}, features: Single},
+	{should: true, pattern: "a.?.?", match: "a.b.c", delimiters: []rune{'.'}, features: Single},
+	{should: true, pattern: "*?at", match: "x日at", features: Single},
+	{should: true, pattern: "*?", match: "abc", features: Single},
+	{should: true, pattern: "?*?", match: "ab", features: Single},
+	{should: false, pattern: "?*?", match: "a", features: Single},
+	{should: true, pattern: "*//?*.example.com", match: "https://w.example.com", features: Single},
+
+	{should: true, pattern: `a\*b`, match: "a*b", features: Escape},
+	{should: false, pattern: `a\*b`, match: "acb", features: Escape},
+	{should: true, pattern: `\*`, match: "*", features: Escape},
+	{should: true, pattern: `\\*`, match: `\abc`, features: Escape},
+	{should: true, pattern: `a\?c`, match: "a?c", features: Single | Escape},
+	{should: false, pattern: `a\?c`, match: "abc", features: Single | Escape},
+	{should: true, pattern: `\a\b\c`, match: "abc", features: Escape},
+	{should: true, pattern: `*\*.example.com`, match: "api*.example.com", features: Escape},
+	{should: false, pattern: `*\*.example.com`, match: "api.example.com", features: Escape},
+
+	{should: true, pattern: "**", match: "a.b.c", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "a.**", match: "a.b.c", delimiters: []rune{'.'}, features: Super},
+	{should: false, pattern: "a.**", match: "b.b.c", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "**.com", match: "a.b.com", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "a.**.c", match: "a.b.b.c", delimiters: []rune{'.'}, features: Super},
+	{should: false, pattern: "a.**.c", match: "a.c", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "a.*.**", match: "a.b.c.d", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "a.***", match: "a.b.c", delimiters: []rune{'.'}, features: Super},
+	{should: true, pattern: "/api/**/*.json", match: "/api/v1/users/list.json", delimiters: []rune{'/'}, features: Super},
+	{should: false, pattern: "/api/**/*.json", match: "/api/v1/users/list.json/x", delimiters: []rune{'/'}, features: Super},
+	{should: true, pattern: "**test**", match: "this is a test", features: Super},
+	{should: true, pattern: `\**`, match: "*abc", delimiters: []rune{'.'}, features: Super | Escape},
+	{should: false, pattern: `\**`, match: "*a.bc", delimiters: []rune{'.'}, features: Super | Escape},
+
+	{should: true, pattern: "[abc]", match: "b", features: Classes},
+	{should: false, pattern: "[abc]", match: "d", features: Classes},
+	{should: false, pattern: "[!abc]", match: "b", features: Classes},
+	{should: true, pattern: "[^abc]", match: "d", features: Classes},
+	{should: true, pattern: "[a-c]at", match: "bat", features: Classes},
+	{should: false, pattern: "[a-c]at", match: "fat", features: Classes},
+	{should: true, pattern: "[!a-c]at", match: "fat", features: Classes},
+	{should: true, pattern: "[日-語]", match: "本", features: Classes},
+	{should: false, pattern: "[!日-語]", match: "本", features: Classes},
+	{should: true, pattern: "a[.]b", match: "a.b", delimiters: []rune{'.'}, features: Classes},
+	{should: false, pattern: "a[!x]b", match: "a.b", delimiters: []rune{'.'}, features: Classes},
+	{should: false, pattern: "a[!x-z]b", match: "a.b", delimiters: []rune{'.'}, features: Classes},
+	{should: true, pattern: "a[!x-z]b", match: "a.b", features: Classes},
+	{should: true, pattern: "*[0-9].log", match: "app.3.log", features: Classes},
+	{should: true, pattern: "[a-z][0-9]", match: "x7", features: Classes},
+	{should: true, pattern: "[a-c]", match: "[a-c]"},
+	{should: true, pattern: `[\]]`, match: "]", features: Classes | Escape},
+	{should: true, pattern: "x]", match: "x]", features: Classes},
+	{should: true, pattern: "[a-z0-9]", match: "7", features: Classes},
+	{should: true, pattern: "[a-z0-9]", match: "q", features: Classes},
+	{should: false, pattern: "[a-z0-9]", match: "Q", features: Classes},
+	{should: true, pattern: "[a-zA-Z]", match: "Q", features: Classes},
+	{should: true, pattern: "*.[0-9a-f]", match: "obj.c", features: Classes},
+	{should: false, pattern: "*.[0-9a-f]", match: "obj.o", features: Classes},
+	{should: true, pattern: "[a-z_0-9.]", match: "_", features: Classes},
+	{should: true, pattern: "[a-z_0-9.]", match: ".", features: Classes},
+	{should: false, pattern: "[!a-z0-9]", match: "x", features: Classes},
+	{should: true, pattern: "[!a-z0-9]", match: "X", features: Classes},
+	{should: false, pattern: "a[!x-z0-9]b", match: "a.b", delimiters: []rune{'.'}, features: Classes},
+	{should: true, pattern: "[a-]", match: "-", features: Classes},
+	{should: true, pattern: "[-a]", match: "-", features: Classes},
+	{should: true, pattern: "[!-a]", match: "b", features: Classes},
+	{should: false, pattern: "[!-a]", match: "-", features: Classes},
+	{should: true, pattern: "[a-c-e]", match: "-", features: Classes},
+	{should: false, pattern: "[a-c-e]", match: "d", features: Classes},
+	{should: true, pattern: `[a\-z]`, match: "-", features: Classes | Escape},
+	{should: false, pattern: `[a\-z]`, match: "b", features: Classes | Escape},
+	{should: true, pattern: "[a-cx-z]", match: "Y", features: Classes | CaseFold},
+	{should: false, pattern: "[!a-cx-z]", match: "Y", features: Classes | CaseFold},
+
+	{should: true, pattern: "{a,b}", match: "a", features: Alternates},
+	{should: true, pattern: "{a,b}", match: "b", features: Alternates},
+	{should: false, pattern: "{a,b}", match: "c", features: Alternates},
+	{should: true, pattern: "{a,b}", match: "{a,b}"},
+	{should: true, pattern: "api.{prod,staging}.example.com", match: "api.staging.example.com", delimiters: []rune{'.'}, features: Alternates},
+	{should: false, pattern: "api.{prod,staging}.example.com", match: "api.dev.example.com", delimiters: []rune{'.'}, features: Alternates},
+	{should: true, pattern: "{api.prod,api.staging}.example.com", match: "api.prod.example.com", features: Alternates},
+	{should: false, pattern: "{api.prod,api.staging}.example.com", match: "api.example.com", features: Alternates},
+	{should: true, pattern: "{*.jpg,*.jpeg}", match: "photo.jpeg", features: Alternates},
+	{should: false, pattern: "{*.jpg,*.jpeg}", match: "photo.png", features: Alternates},
+	{should: true, pattern: "ab{,ab}", match: "ab", features: Alternates},
+	{should: true, pattern: "ab{,ab}", match: "abab", features: Alternates},
+	{should: false, pattern: "ab{,ab}", match: "aba", features: Alternates},
+	{should: true, pattern: "{aXa,a}", match: "a", features: Alternates},
+	{should: true, pattern: "{aXa,a}", match: "aXa", features: Alternates},
+	{should: true, pattern: "{aba,aa}", match: "aa", features: Alternates},
+	{should: true, pattern: "{aba,aa}", match: "aba", features: Alternates},
+	{should: false, pattern: "{aba,aa}", match: "aaa", features: Alternates},
+	{should: true, pattern: "{a,{b,c}}x", match: "cx", features: Alternates},
+	{should: true, pattern: "log*{,.gz}", match: "log", features: Alternates},
+	{should: true, pattern: "log*{,.gz}", match: "log.1.gz", features: Alternates},
+	{should: true, pattern: "foo*{.txt,}", match: "foo", features: Alternates},
+	{should: true, pattern: "v*{-rc,}", match: "v", features: Alternates},
+	{should: true, pattern: "x*{a,}y", match: "xy", features: Alternates},
+	{should: false, pattern: "x*{a,}y", match: "x", features: Alternates},
+	{should: true, pattern: "*{a,}", match: "", features: Alternates},
+	{should: true, pattern: "{a,}*", match: "", features: Alternates},
+	{should: true, pattern: "a.*{,.b}", match: "a.", delimiters: []rune{'.'}, features: Alternates},
+	{should: true, pattern: "{日本,日語}", match: "日語", features: Alternates},
+	{should: true, pattern: "a,b", match: "a,b", features: Alternates},
+	{should: true, pattern: "a}", match: "a}", features: Alternates},
+	{should: true, pattern: `{a\,b,c}`, match: "a,b", features: Alternates | Escape},
+	{should: true, pattern: "{[a-c],?x}", match: "b", features: Alternates | Classes | Single},
+	{should: true, pattern: "{[a-c],?x}", match: "zx", features: Alternates | Classes | Single},
+	{should: false, pattern: "{[a-c],?x}", match: "z", features: Alternates | Classes | Single},
+	{should: true, pattern: "*.{com,net}", match: "a.b.net", features: Alternates},
+	{should: false, pattern: "*.{com,net}", match: "a.b.net", delimiters: []rune{'.'}, features: Alternates},
+
+	{should: false, pattern: "abc", match: "ABC"},
+	{should: true, pattern: "abc", match: "ABC", features: CaseFold},
+	{should: true, pattern: "abc", match: "aBc", features: CaseFold},
+	{should: false, pattern: "abc", match: "abd", features: CaseFold},
+	{should: true, pattern: "*.Example.COM", match: "api.example.com", delimiters: []rune{'.'}, features: CaseFold},
+	{should: true, pattern: "API.*", match: "api.example", delimiters: []rune{'.'}, features: CaseFold},
+	{should: false, pattern: "API.*", match: "api.example.com", delimiters: []rune{'.'}, features: CaseFold},
+	{should: true, pattern: "https://*.google.*", match: "HTTPS://Account.Google.COM", features: CaseFold},
+	{should: true, pattern: "*TEST*", match: "this is a test case", features: CaseFold},
+	{should: true, pattern: "a?c", match: "ABC", features: Single | CaseFold},
+	{should: true, pattern: "straße", match: "STRAßE", features: CaseFold},
+	{should: true, pattern: "ΣΊΣΥΦΟΣ", match: "σίσυφος", features: CaseFold},
+	{should: true, pattern: "ΣΊΣΥΦΟΣ", match: "σίσυφοσ", features: CaseFold},
+	{should: true, pattern: "kelvin", match: "\u212aelvin", features: CaseFold},
+	{should: true, pattern: "*s", match: "bus", features: CaseFold},
+	{should: true, pattern: "*s", match: "buſ", features: CaseFold},
+	{should: true, pattern: "ſ*", match: "Sun", features: CaseFold},
+	{should: true, pattern: "*ſ*", match: "mass", features: CaseFold},
+	{should: true, pattern: "[a-c]at", match: "BAT", features: Classes | CaseFold},
+	{should: false, pattern: "[!a-c]at", match: "BAT", features: Classes | CaseFold},
+	{should: true, pattern: "[xyz]", match: "Y", features: Classes | CaseFold},
+	{should: true, pattern: "[k]", match: "\u212a", features: Classes | CaseFold},
+	{should: true, pattern: "{jpg,png}", match: "PNG", features: Alternates | CaseFold},
+	{should: true, pattern: "*.{jpg,jpeg}", match: "photo.JPEG", features: Alternates | CaseFold},
 }
 
 func TestGlob(t *testing.T) {
-	for _, test := range []test{
-		{should: true, pattern: "* cat * eyes", match: "my cat has very bright eyes"},
-		{should: true, pattern: "", match: ""},
-		{should: true, pattern: `a*b`, match: "a*b"},
-		{should: false, pattern: `a\*b`, match: "a*b"},
-		{should: true, pattern: `a\*b`, match: `a\*b`},
-
-		{should: true, pattern: "*ä", match: "åä"},
-		{should: true, pattern: "abc", match: "abc"},
-		{should: true, pattern: "a*c", match: "abc"},
-		{should: true, pattern: "a*c", match: "a12345c"},
-		{should: false, pattern: "a?c", match: "a1c"},
-		{should: true, pattern: "a.*", match: "a.b.c"},
-		{should: true, pattern: "a.b", match: "a.b", delimiters: []rune{'.'}},
-		{should: true, pattern: "a.*", match: "a.b", delimiters: []rune{'.'}},
-		{should: false, pattern: "a.**", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "a.?.c", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: true, pattern: "a.*.c", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "a.?.?", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "a.*", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "?at", match: "cat"},
-		{should: true, pattern: "ca*", match: "ca*t"},
-		{should: true, pattern: "ca*", match: "ca*"},
-		{should: true, pattern: "c*t", match: "ca*aaaaaaaat"},
-		{should: false, pattern: "?at", match: "fat"},
-		{should: true, pattern: "*", match: "abc"},
-		{should: false, pattern: `\*`, match: "*"},
-		{should: false, pattern: "**", match: "a.b.c", delimiters: []rune{'.'}},
-
-		{should: false, pattern: "?at", match: "at"},
-		{should: false, pattern: "?at", match: "fat", delimiters: []rune{'f'}},
-		{should: false, pattern: "*at", match: "fat", delimiters: []rune{'f'}},
-		{should: false, pattern: "a.*", match: "a.b.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "a.?.c", match: "a.bb.c", delimiters: []rune{'.'}},
-		{should: true, pattern: "a.*.c", match: "a.bb.c", delimiters: []rune{'.'}},
-		{should: false, pattern: "*", match: "a.b.c", delimiters: []rune{'.'}},
-
-		{should: true, pattern: "*test", match: "this is a test"},
-		{should: true, pattern: "this*", match: "this is a test"},
-		{should: true, pattern: "*is *", match: "this is a test"},
-		{should: true, pattern: "*is*a*", match: "this is a test"},
-		{should: true, pattern: "**test**", match: "this is a test"},
-		{should: true, pattern: "**is**a***test*", match: "this is a test"},
-
-		{should: false, pattern: "*is", match: "this is a test"},
-		{should: false, pattern: "*no*", match: "this is a test"},
-		{should: true, pattern: "*abc", match: "abcabc"},
-		{should: true, pattern: "/*", match: "/rate"},
-
-		{should: true, pattern: "*//*.example.com", match: "https://www.example.com"},
-		{should: true, pattern: "*//*.example.com", match: "https://www.example.com", delimiters: []rune{'.'}},
-		{should: true, pattern: "*//*example.com", match: "https://www.example.com"},
-		{should: false, pattern: "*//*example.com", match: "https://www.example.com", delimiters: []rune{'.'}},
-		{should: false, pattern: "*//*.example.com", match: "http://example.com"},
-		{should: false, pattern: "*//*.example.com", match: "http://example.com.net"},
-		{should: true, pattern: "*//*example.com", match: "http://example.com"},
-	} {
+	for _, test := range globTests {
 		t.Run(test.pattern, func(t *testing.T) {
-			g := MustCompile(test.pattern, test.delimiters...)
+			g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
 			result := g.Match(test.match)
 			if result != test.should {
 				t.Errorf(
@@ -78,7 +235,275 @@ func TestGlob(t *testing.T) {
 					test.pattern, test.match, test.should, result, g,
 				)
 			}
+
+			if g.MatchBytes([]byte(test.match)) != result {
+				t.Errorf("pattern %q matching bytes %q differs from matching string\n%s", test.pattern, test.match, g)
+			}
+
+			if _, ok := g.Captures(test.match); ok != result {
+				t.Errorf("pattern %q captures in %q reports %v while match is %v\n%s", test.pattern, test.match, ok, result, g)
+			}
+
+			if result {
+				for i := range test.match {
+					if !g.CouldMatchPrefix(test.match[:i]) {
+						t.Errorf("pattern %q could not match prefix %q of matching %q\n%s", test.pattern, test.match[:i], test.match, g)
+					}
+				}
+			}
+
+			loc := g.FindStringIndex(test.match)
+			if exp := bruteForceFind(g, test.match, false); !reflect.DeepEqual(loc, exp) {
+				t.Errorf(
+					"pattern %q find in %q should be %v but got %v\n%s",
+					test.pattern, test.match, exp, loc, g,
+				)
+			}
+		})
+	}
+}
+
+func TestCouldMatchPrefix(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		prefix     string
+		should     bool
+		delimiters []rune
+		features   Feature
+	}{
+		{pattern: "abc", prefix: "", should: true},
+		{pattern: "abc", prefix: "ab", should: true},
+		{pattern: "abc", prefix: "abc", should: true},
+		{pattern: "abc", prefix: "abcd", should: false},
+		{pattern: "abc", prefix: "b", should: false},
+		{pattern: "abc", prefix: "AB", should: true, features: CaseFold},
+		{pattern: "api.*", prefix: "ap", should: true, delimiters: []rune{'.'}},
+		{pattern: "api.*", prefix: "api.github", should: true, delimiters: []rune{'.'}},
+		{pattern: "api.*", prefix: "api.github.", should: false, delimiters: []rune{'.'}},
+		{pattern: "api.*", prefix: "web", should: false, delimiters: []rune{'.'}},
+		{pattern: "*.com", prefix: "example", should: true, delimiters: []rune{'.'}},
+		{pattern: "*.com", prefix: "example.c", should: true, delimiters: []rune{'.'}},
+		{pattern: "*.com", prefix: "example.n", should: false, delimiters: []rune{'.'}},
+		{pattern: "*.com", prefix: "a.b", should: false, delimiters: []rune{'.'}},
+		{pattern: "src/*/*.go", prefix: "src/", should: true, delimiters: []rune{'/'}},
+		{pattern: "src/*/*.go", prefix: "src/pkg/", should: true, delimiters: []rune{'/'}},
+		{pattern: "src/*/*.go", prefix: "src/pkg/x/", should: false, delimiters: []rune{'/'}},
+		{pattern: "src/*/*.go", prefix: "lib/", should: false, delimiters: []rune{'/'}},
+		{pattern: "src/**/*.go", prefix: "src/a/b/c/", should: true, delimiters: []rune{'/'}, features: Super},
+		{pattern: "src/**/*.go", prefix: "lib/", should: false, delimiters: []rune{'/'}, features: Super},
+		{pattern: "a?c", prefix: "ax", should: true, features: Single},
+		{pattern: "a?c", prefix: "axd", should: false, features: Single},
+		{pattern: "?*b", prefix: "\xffb", should: true, features: Single},
+		{pattern: "[a-c]x", prefix: "d", should: false, features: Classes},
+		{pattern: "{api,web}.*", prefix: "we", should: true, delimiters: []rune{'.'}, features: Alternates},
+		{pattern: "{api,web}.*", prefix: "db", should: false, delimiters: []rune{'.'}, features: Alternates},
+		{pattern: "ab*ef", prefix: "abcd", should: true},
+		{pattern: "ab*ef", prefix: "ac", should: false},
+	} {
+		g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+		if act := g.CouldMatchPrefix(test.prefix); act != test.should {
+			t.Errorf("pattern %q could match prefix %q should be %v but got %v\n%s", test.pattern, test.prefix, test.should, act, g)
+		}
+	}
+}
+
+func TestMatchBytesAllocs(t *testing.T) {
+	if raceEnabled {
+		t.Skip("DFA caches are pooled, so they are rebuilt with the race detector")
+	}
+	for name, pattern := range testPatterns {
+		g := MustCompile(pattern.pattern)
+		b := []byte(pattern.text)
+		if n := testing.AllocsPerRun(10, func() { g.MatchBytes(b) }); n != 0 {
+			t.Errorf("%s: pattern %q allocates %v times matching bytes", name, pattern.pattern, n)
+		}
+	}
+}
+
+func TestGlobMatcher(t *testing.T) {
+	g := MustCompile("*.github.com")
+	m, ok := g.(match.Matcher)
+	if !ok {
+		t.Fatalf("compiled glob does not implement match.Matcher")
+	}
+	if !m.Match("api.github.com") {
+		t.Errorf("expected %q to match", "api.github.com")
+	}
+	if index, segments := m.Index("see api.github.com"); index != 0 || !reflect.DeepEqual(segments, []int{18}) {
+		t.Errorf("unexpected index: %d %v", index, segments)
+	}
+}
+
+func TestGlobDFA(t *testing.T) {
+	for _, test := range globTests {
+		tree, err := syntax.ParseMode(test.pattern, test.features.lexerMode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		m, err := compiler.CompileOptions(tree, compiler.Options{
+			Separators: test.delimiters,
+			CaseFold:   test.features&CaseFold != 0,
+			Backend:    compiler.BackendDFA,
 		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if act := m.Match(test.match); act != test.should {
+			t.Errorf("pattern %q matching %q should be %v but got %v\n%s", test.pattern, test.match, test.should, act, m)
+		}
+		for _, s := range []string{test.match, "x" + test.match + test.match} {
+			index, segments := m.Index(s)
+			fi, fs := m.(match.DFA).Fallback.Index(s)
+			if index != fi || !reflect.DeepEqual(segments, fs) {
+				t.Errorf("pattern %q Index(%q) = %d, %v; fallback reports %d, %v", test.pattern, s, index, segments, fi, fs)
+			}
+		}
+		if test.should {
+			for i := range test.match {
+				if !m.CouldMatchPrefix(test.match[:i]) {
+					t.Errorf("pattern %q could not match prefix %q of matching %q\n%s", test.pattern, test.match[:i], test.match, m)
+				}
+			}
+		}
+	}
+}
+
+func TestCompileFeaturesError(t *testing.T) {
+	for _, pattern := range []string{
+		`\`,
+		`abc\`,
+		`[`,
+		`[abc`,
+		`[]`,
+		`[!]`,
+		`[z-a]`,
+		`[a-z0-`,
+		`[0-9z-a]`,
+		`{a,b`,
+		`{a,{b}`,
+	} {
+		if _, err := CompileFeatures(pattern, Escape|Classes|Alternates); err == nil {
+			t.Errorf("expected error for pattern %q", pattern)
+		}
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		offset  int
+		column  int
+		token   lexer.TokenType
+		err     string
+	}{
+		{
+			pattern: `abc\`, offset: 3, column: 4, token: lexer.Error,
+			err: "unexpected end of pattern after escape character at column 4:\n\tabc\\\n\t   ^",
+		},
+		{
+			pattern: `a*[bc`, offset: 2, column: 3, token: lexer.Error,
+			err: "unexpected end of pattern: unclosed character class at column 3:\n\ta*[bc\n\t  ^",
+		},
+		{
+			pattern: `ä[z-a]`, offset: 5, column: 5, token: lexer.RangeHi,
+			err: "hi character 'a' should be greater than lo 'z' at column 5:\n\tä[z-a]\n\t    ^",
+		},
+		{
+			pattern: "x{a,{b}", offset: 1, column: 2, token: lexer.Error,
+			err: "unexpected end of pattern: unclosed alternation at column 2:\n\tx{a,{b}\n\t ^",
+		},
+		{
+			pattern: "\t[]", offset: 1, column: 2, token: lexer.RangeOpen,
+			err: "could not parse character class at column 2:\n\t\t[]\n\t\t^",
+		},
+		{
+			pattern: "a\xffb", offset: 1, column: 2, token: lexer.Error,
+			err: "could not read rune at column 2:\n\ta\xffb\n\t ^",
+		},
+	} {
+		_, err := CompileFeatures(test.pattern, Escape|Classes|Alternates)
+
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("pattern %q: expected *SyntaxError, got %v", test.pattern, err)
+			continue
+		}
+		if serr.Offset != test.offset || serr.Column != test.column || serr.Token.Type != test.token {
+			t.Errorf(
+				"pattern %q: unexpected position: exp: %d %d %s, act: %d %d %s",
+				test.pattern, test.offset, test.column, test.token, serr.Offset, serr.Column, serr.Token.Type,
+			)
+		}
+		if act := serr.Error(); act != test.err {
+			t.Errorf("pattern %q: unexpected error:\nexp: %q\nact: %q", test.pattern, test.err, act)
+		}
+	}
+}
+
+func TestQuoteMeta(t *testing.T) {
+	for id, test := range []struct {
+		in, out string
+	}{
+		{
+			in:  `[foo*]`,
+			out: `\[foo\*\]`,
+		},
+		{
+			in:  `a?b\c`,
+			out: `a\?b\\c`,
+		},
+		{
+			in:  `*.日本.com`,
+			out: `\*.日本.com`,
+		},
+		{
+			in:  `abc`,
+			out: `abc`,
+		},
+	} {
+		act := QuoteMeta(test.in)
+		if act != test.out {
+			t.Errorf("#%d QuoteMeta(%q) = %q; want %q", id, test.in, act, test.out)
+		}
+
+		g, err := CompileFeatures(act, Single|Escape)
+		if err != nil {
+			t.Errorf("#%d _, err := CompileFeatures(QuoteMeta(%q)) = %s; want nil", id, test.in, err)
+			continue
+		}
+		if !g.Match(test.in) {
+			t.Errorf("#%d CompileFeatures(QuoteMeta(%q)).Match(%q) = false; want true", id, test.in, test.in)
+		}
+	}
+}
+
+func TestQuoteMetaFeatures(t *testing.T) {
+	for id, test := range []struct {
+		in       string
+		features Feature
+		out      string
+	}{
+		{in: `a,b?`, features: Escape, out: `a,b?`},
+		{in: `a,b?`, features: Single, out: `a,b\?`},
+		{in: `a,{b}?`, features: Alternates | Single, out: `a\,\{b\}\?`},
+		{in: `*[x]\`, features: 0, out: `\*[x]\\`},
+		{in: `*[x]\`, features: Classes, out: `\*\[x\]\\`},
+		{in: `**`, features: Super, out: `\*\*`},
+	} {
+		act := QuoteMetaFeatures(test.in, test.features)
+		if act != test.out {
+			t.Errorf("#%d QuoteMetaFeatures(%q) = %q; want %q", id, test.in, act, test.out)
+		}
+
+		g, err := CompileFeatures(act, test.features|Escape)
+		if err != nil {
+			t.Errorf("#%d _, err := CompileFeatures(QuoteMetaFeatures(%q)) = %s; want nil", id, test.in, err)
+			continue
+		}
+		if !g.Match(test.in) {
+			t.Errorf("#%d CompileFeatures(QuoteMetaFeatures(%q)).Match(%q) = false; want true", id, test.in, test.in)
+		}
 	}
 }
 
@@ -95,6 +520,13 @@ var (
 		`def-false`:    {`*def`, "af"},
 		`abef-true`:    {`ab*ef`, "abcdef"},
 		`abef-false`:   {`ab*ef`, "af"},
+
+		`contains-true`:       {`*github*`, "https://api.github.com/repos"},
+		`substrings-true`:     {`*api*github*repos*`, "https://api.github.com/repos"},
+		`prefix-suffix-true`:  {`https://*repos`, "https://api.github.com/repos"},
+		`contains-false`:      {`*gitlab*`, "https://api.github.com/repos"},
+		`substrings-false`:    {`*api*gitlab*repos*`, "https://api.github.com/repos"},
+		`prefix-suffix-false`: {`https://*issues`, "https://api.github.com/repos"},
 	}
 )
 
@@ -154,6 +586,18 @@ func BenchmarkGlobMatchGoogleURL_False(b *testing.B) {
 	}
 }
 
+func BenchmarkGlobMatchGoogleURL_CaseFold(b *testing.B) {
+	pattern := testPatterns["google-true"]
+	c := MustCompileFeatures(pattern.pattern, CaseFold)
+	text := "HTTPS://Account.Google.COM"
+
+	b.ReportAllocs()
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Match(text)
+	}
+}
+
 func BenchmarkGlobMatchAbc(b *testing.B) {
 	pattern := testPatterns["abc-true"]
 	c := MustCompile(pattern.pattern)
@@ -213,3 +657,112 @@ func BenchmarkGlobMatchAbdef_Flase(b *testing.B) {
 		c.Match(pattern.text)
 	}
 }
+
+func BenchmarkGlobMatchBytesGoogleURL_True(b *testing.B) {
+	pattern := testPatterns["google-true"]
+	c := MustCompile(pattern.pattern)
+	text := []byte(pattern.text)
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.MatchBytes(text)
+	}
+}
+
+func BenchmarkGlobMatchBytesGoogleURL_False(b *testing.B) {
+	pattern := testPatterns["google-false"]
+	c := MustCompile(pattern.pattern)
+	text := []byte(pattern.text)
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.MatchBytes(text)
+	}
+}
+
+func benchmarkGlobMatch(b *testing.B, name string, m match.Matcher) {
+	pattern := testPatterns[name]
+	var g Glob = glob{matcher: m}
+	if m == nil {
+		g = MustCompile(pattern.pattern)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		g.Match(pattern.text)
+	}
+}
+
+func BenchmarkGlobMatchContains(b *testing.B) {
+	b.Run("Contains", func(b *testing.B) { benchmarkGlobMatch(b, "contains-true", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "contains-true", match.NewBTree(match.NewText("github"), match.NewAny(nil), match.NewAny(nil)))
+	})
+}
+
+func BenchmarkGlobMatchContains_False(b *testing.B) {
+	b.Run("Contains", func(b *testing.B) { benchmarkGlobMatch(b, "contains-false", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "contains-false", match.NewBTree(match.NewText("gitlab"), match.NewAny(nil), match.NewAny(nil)))
+	})
+}
+
+func BenchmarkGlobMatchSubstrings(b *testing.B) {
+	b.Run("Substrings", func(b *testing.B) { benchmarkGlobMatch(b, "substrings-true", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "substrings-true", substringsBTree("api", "github", "repos"))
+	})
+}
+
+func BenchmarkGlobMatchSubstrings_False(b *testing.B) {
+	b.Run("Substrings", func(b *testing.B) { benchmarkGlobMatch(b, "substrings-false", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "substrings-false", substringsBTree("api", "gitlab", "repos"))
+	})
+}
+
+func BenchmarkGlobMatchPrefixSuffix(b *testing.B) {
+	b.Run("PrefixSuffix", func(b *testing.B) { benchmarkGlobMatch(b, "prefix-suffix-true", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "prefix-suffix-true", match.NewBTree(match.NewText("repos"), match.NewPrefixAny("https://", nil), nil))
+	})
+}
+
+func BenchmarkGlobMatchPrefixSuffix_False(b *testing.B) {
+	b.Run("PrefixSuffix", func(b *testing.B) { benchmarkGlobMatch(b, "prefix-suffix-false", nil) })
+	b.Run("BTree", func(b *testing.B) {
+		benchmarkGlobMatch(b, "prefix-suffix-false", match.NewBTree(match.NewText("issues"), match.NewPrefixAny("https://", nil), nil))
+	})
+}
+
+// substringsBTree builds the tree `*a*b*c*` was compiled to before Substrings matcher.
+func substringsBTree(a, b, c string) match.Matcher {
+	any := match.NewAny(nil)
+	return match.NewBTree(match.NewText(b), match.NewBTree(match.NewText(a), any, any), match.NewBTree(match.NewText(c), any, any))
+}
+
+// pathological pattern takes exponential time to backtrack on the text
+var pathological = struct {
+	pattern string
+	text    string
+}{`b*a*a*a*a*a*a*`, strings.Repeat("a", 32)}
+
+func BenchmarkGlobMatchPathological(b *testing.B) {
+	c := MustCompile(pathological.pattern)
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Match(pathological.text)
+	}
+}
+
+func BenchmarkGlobMatchPathological_Tree(b *testing.B) {
+	tree, _ := syntax.Parse(pathological.pattern)
+	c, _ := compiler.CompileOptions(tree, compiler.Options{Backend: compiler.BackendTree})
+
+	b.StartTimer()
+	for i := 0; i < b.N; i++ {
+		c.Match(pathological.text)
+	}
+}
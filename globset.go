@@ -0,0 +1,168 @@
+package glob
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gopherlib/simple-glob/util/ahocorasick"
+)
+
+// GlobSet is a set of patterns that are matched together.
+// Patterns are identified by their index in the slice given to CompileGlobSet.
+//
+// Literal text that every match of a pattern must contain is searched for all
+// patterns at once in a single pass over the string, and only patterns whose
+// literal was found are matched. Patterns without such literal, as well as
+// patterns compiled with CaseFold feature, are always matched.
+type GlobSet struct {
+	globs       []glob
+	specificity []Specificity
+	literals    *ahocorasick.Automaton
+	always      []int
+	// bitsets holds *bitset of candidate patterns reused between calls.
+	bitsets sync.Pool
+}
+
+// CompileGlobSet compiles all patterns with the same options into a GlobSet.
+func CompileGlobSet(patterns []string, opts ...Option) (*GlobSet, error) {
+	set, i, err := compileGlobSet(patterns, opts)
+	if err != nil {
+		return nil, fmt.Errorf("pattern #%d %q: %w", i, patterns[i], err)
+	}
+	return set, nil
+}
+
+// compileGlobSet is CompileGlobSet returning error of a pattern along with its index.
+func compileGlobSet(patterns []string, opts []Option) (*GlobSet, int, error) {
+	set := &GlobSet{
+		globs:       make([]glob, len(patterns)),
+		specificity: make([]Specificity, len(patterns)),
+	}
+
+	var (
+		literals []string
+		indices  []int
+	)
+	for i, pattern := range patterns {
+		g, err := CompileOptions(pattern, opts...)
+		if err != nil {
+			return nil, i, err
+		}
+		set.globs[i] = g.(glob)
+		set.specificity[i] = specificity(set.globs[i].matcher)
+
+		if lit := requiredLiteral(set.globs[i].matcher); lit != "" {
+			literals = append(literals, lit)
+			indices = append(indices, i)
+		} else {
+			set.always = append(set.always, i)
+		}
+	}
+	set.literals = ahocorasick.New(literals, indices)
+	set.bitsets.New = func() interface{} {
+		b := make(bitset, (len(patterns)+63)/64)
+		return &b
+	}
+
+	return set, 0, nil
+}
+
+// MustCompileGlobSet is the same as CompileGlobSet, except that if CompileGlobSet returns error, this will panic
+func MustCompileGlobSet(patterns []string, opts ...Option) *GlobSet {
+	set, err := CompileGlobSet(patterns, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return set
+}
+
+// Len returns the number of patterns in the set.
+func (set *GlobSet) Len() int {
+	return len(set.globs)
+}
+
+// Match reports whether the whole string matches any pattern of the set.
+func (set *GlobSet) Match(s string) bool {
+	return set.first(s, false) != -1
+}
+
+// Matches returns indices of all patterns that match the whole string, in ascending order.
+// A return value of nil indicates no match.
+func (set *GlobSet) Matches(s string) []int {
+	var result []int
+	candidates := set.candidates(s)
+	for i := range set.globs {
+		if candidates.has(i) && set.globs[i].Match(s) {
+			result = append(result, i)
+		}
+	}
+	set.bitsets.Put(candidates)
+	return result
+}
+
+// Best returns the index of the most specific pattern that matches the whole string.
+// Patterns are compared by their Specificity, and of equally specific patterns
+// the one with the lowest index is chosen. It returns false if no pattern matches.
+func (set *GlobSet) Best(s string) (int, bool) {
+	best := -1
+	candidates := set.candidates(s)
+	for i := range set.globs {
+		if !candidates.has(i) || (best != -1 && set.specificity[i].Compare(set.specificity[best]) <= 0) {
+			continue
+		}
+		if set.globs[i].Match(s) {
+			best = i
+		}
+	}
+	set.bitsets.Put(candidates)
+	return best, best != -1
+}
+
+// first returns the index of the first pattern that matches the whole string,
+// or the last one if reverse is set. It returns -1 if no pattern matches.
+func (set *GlobSet) first(s string, reverse bool) int {
+	candidates := set.candidates(s)
+	defer set.bitsets.Put(candidates)
+	for j := range set.globs {
+		i := j
+		if reverse {
+			i = len(set.globs) - 1 - j
+		}
+		if candidates.has(i) && set.globs[i].Match(s) {
+			return i
+		}
+	}
+	return -1
+}
+
+// Specificity returns specificity of the i-th pattern of the set.
+func (set *GlobSet) Specificity(i int) Specificity {
+	return set.specificity[i]
+}
+
+// candidates returns the set of patterns that could match s.
+// The returned bitset is taken from the pool and should be put back to it.
+func (set *GlobSet) candidates(s string) *bitset {
+	result := set.bitsets.Get().(*bitset)
+	b := *result
+	for i := range b {
+		b[i] = 0
+	}
+	for _, i := range set.always {
+		b.set(i)
+	}
+	set.literals.Each(s, b.set)
+	return result
+}
+
+// bitset is a set of small non-negative integers.
+type bitset []uint64
+
+func (b bitset) set(i int) {
+	b[i/64] |= 1 << uint(i%64)
+}
+
+func (b bitset) has(i int) bool {
+	return b[i/64]&(1<<uint(i%64)) != 0
+}
@@ -0,0 +1,167 @@
+package glob
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestGlobSetMatches(t *testing.T) {
+	patterns := []string{
+		"*.example.com",
+		"api.example.com",
+		"*",
+		"api.*",
+		"*.{org,net}",
+		"[a-c]?.example.com",
+		"*.com",
+		"**.example.com",
+		"ab*cd*ef",
+	}
+	for _, test := range []struct {
+		features   Feature
+		delimiters []rune
+		fixture    string
+		matches    []int
+	}{
+		{fixture: "api.example.com", matches: []int{0, 1, 2, 3, 6, 7}},
+		{fixture: "api.example.com", delimiters: []rune{'.'}, matches: []int{0, 1, 7}},
+		{fixture: "a.b.example.com", delimiters: []rune{'.'}, features: Super, matches: []int{7}},
+		{fixture: "example.org", features: Alternates, matches: []int{2, 4}},
+		{fixture: "example.org", matches: []int{2}},
+		{fixture: "bx.example.com", delimiters: []rune{'.'}, features: Classes | Single, matches: []int{0, 5, 7}},
+		{fixture: "API.EXAMPLE.COM", delimiters: []rune{'.'}, features: CaseFold, matches: []int{0, 1, 7}},
+		{fixture: "abXcdYef", delimiters: []rune{'.'}, matches: []int{2, 8}},
+		{fixture: "a.b", delimiters: []rune{'.'}, matches: nil},
+	} {
+		t.Run(fmt.Sprintf("%s/%v", test.fixture, test.features), func(t *testing.T) {
+			set := MustCompileGlobSet(patterns, WithFeatures(test.features), WithSeparators(test.delimiters...))
+
+			var exp []int
+			for i, pattern := range patterns {
+				if MustCompileFeatures(pattern, test.features, test.delimiters...).Match(test.fixture) {
+					exp = append(exp, i)
+				}
+			}
+			if !reflect.DeepEqual(exp, test.matches) {
+				t.Fatalf("bad test case: separate globs give %v", exp)
+			}
+
+			if act := set.Matches(test.fixture); !reflect.DeepEqual(act, test.matches) {
+				t.Errorf("unexpected matches: exp: %v, act: %v", test.matches, act)
+			}
+			if act := set.Match(test.fixture); act != (len(test.matches) > 0) {
+				t.Errorf("unexpected match: %v", act)
+			}
+		})
+	}
+}
+
+func TestGlobSetError(t *testing.T) {
+	if _, err := CompileGlobSet([]string{"a", "[a"}, WithFeatures(Classes)); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
+
+func TestGlobSetAllocs(t *testing.T) {
+	patterns := make([]string, 100)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("*.host%d.com", i)
+	}
+	set := MustCompileGlobSet(patterns, WithSeparators('.'))
+	if best, ok := set.Best("api.host70.com"); best != 70 || !ok {
+		t.Errorf("unexpected best: exp: %d %v, act: %d %v", 70, true, best, ok)
+	}
+
+	if raceEnabled {
+		t.Skip("candidate sets are pooled, so they are allocated with the race detector")
+	}
+	if n := testing.AllocsPerRun(10, func() { set.Match("api.host70.com") }); n != 0 {
+		t.Errorf("Match allocates %v times", n)
+	}
+	if n := testing.AllocsPerRun(10, func() { set.Best("api.host70.com") }); n != 0 {
+		t.Errorf("Best allocates %v times", n)
+	}
+}
+
+func TestRequiredLiteral(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		features Feature
+		literal  string
+	}{
+		{pattern: "abc", literal: "abc"},
+		{pattern: "*.example.com", literal: ".example.com"},
+		{pattern: "api.*", literal: "api."},
+		{pattern: "ab*cdef", literal: "cdef"},
+		{pattern: "a*bcd*ef", literal: "bcd"},
+		{pattern: "*", literal: ""},
+		{pattern: "{abc,abd}", features: Alternates, literal: ""},
+		{pattern: "abc", features: CaseFold, literal: ""},
+	} {
+		g := MustCompileFeatures(test.pattern, test.features).(glob)
+		if act := requiredLiteral(g.matcher); act != test.literal {
+			t.Errorf("pattern %q: unexpected literal: exp: %q, act: %q\n%s", test.pattern, test.literal, act, g)
+		}
+	}
+}
+
+func benchmarkPatterns(n int) []string {
+	patterns := make([]string, n)
+	for i := range patterns {
+		patterns[i] = fmt.Sprintf("*.host%d.example.com", i)
+	}
+	return patterns
+}
+
+func BenchmarkGlobSetMatches(b *testing.B) {
+	set := MustCompileGlobSet(benchmarkPatterns(1000), WithSeparators('.'))
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		set.Matches("api.host500.example.com")
+	}
+}
+
+func BenchmarkGlobSetMatchesLoop(b *testing.B) {
+	var globs []Glob
+	for _, pattern := range benchmarkPatterns(1000) {
+		globs = append(globs, MustCompile(pattern, '.'))
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var result []int
+		for j, g := range globs {
+			if g.Match("api.host500.example.com") {
+				result = append(result, j)
+			}
+		}
+	}
+}
+
+func TestGlobSetBest(t *testing.T) {
+	for _, test := range []struct {
+		patterns []string
+		features Feature
+		fixture  string
+		best     int
+		ok       bool
+	}{
+		{patterns: []string{"*.example.com", "api.example.com"}, fixture: "api.example.com", best: 1, ok: true},
+		{patterns: []string{"*", "*.com", "*.example.com"}, fixture: "api.example.com", best: 2, ok: true},
+		{patterns: []string{"**.com", "*.com"}, features: Super, fixture: "example.com", best: 1, ok: true},
+		{patterns: []string{"a*c", "a?c"}, features: Single, fixture: "abc", best: 1, ok: true},
+		{patterns: []string{"*.com", "*.com"}, fixture: "example.com", best: 0, ok: true},
+		{patterns: []string{"x*", "*y"}, fixture: "xy", best: 0, ok: true},
+		{patterns: []string{"*.org", "api.*.com"}, fixture: "example.com", best: -1, ok: false},
+	} {
+		set := MustCompileGlobSet(test.patterns, WithFeatures(test.features), WithSeparators('.'))
+		best, ok := set.Best(test.fixture)
+		if best != test.best || ok != test.ok {
+			t.Errorf("%q: unexpected best for %q: exp: %d %v, act: %d %v", test.patterns, test.fixture, test.best, test.ok, best, ok)
+		}
+	}
+}
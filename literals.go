@@ -0,0 +1,152 @@
+package glob
+
+import (
+	"github.com/gopherlib/simple-glob/match"
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+func (g glob) LiteralPrefix() (string, bool) {
+	return literalPrefix(g.matcher)
+}
+
+func (g glob) RequiredLiterals() []string {
+	return requiredLiterals(g.matcher, nil)
+}
+
+// literalPrefix returns case-sensitive text that every string matched by m begins with.
+// It also reports whether m matches only that text.
+func literalPrefix(m match.Matcher) (string, bool) {
+	switch m := m.(type) {
+	case match.Nothing:
+		return "", true
+	case match.Text:
+		if !m.Fold {
+			return m.Str, true
+		}
+	case match.PrefixAny:
+		if !m.Fold {
+			return m.Prefix, false
+		}
+	case match.PrefixSuffix:
+		if !m.Fold {
+			return m.Prefix, false
+		}
+	case match.DFA:
+		return literalPrefix(m.Fallback)
+	case match.LengthLimit:
+		return literalPrefix(m.Matcher)
+	case match.Row:
+		return concatPrefix(m.Matchers...)
+	case match.BTree:
+		parts := make([]match.Matcher, 0, 3)
+		if m.Left != nil {
+			parts = append(parts, m.Left)
+		}
+		parts = append(parts, m.Value)
+		if m.Right != nil {
+			parts = append(parts, m.Right)
+		}
+		return concatPrefix(parts...)
+	case match.AnyOf:
+		if len(m.Matchers) == 1 {
+			return literalPrefix(m.Matchers[0])
+		}
+		// every string begins with the prefix of one of the alternatives
+		var prefix string
+		for i, c := range m.Matchers {
+			p, _ := literalPrefix(c)
+			if i == 0 {
+				prefix = p
+				continue
+			}
+			prefix = sutil.CommonPrefix(prefix, p)
+		}
+		return prefix, false
+	}
+	return "", false
+}
+
+// concatPrefix returns literal prefix of matchers matching consecutive parts of a string.
+func concatPrefix(matchers ...match.Matcher) (string, bool) {
+	var prefix string
+	for _, m := range matchers {
+		p, complete := literalPrefix(m)
+		prefix += p
+		if !complete {
+			return prefix, false
+		}
+	}
+	return prefix, true
+}
+
+// requiredLiterals appends to dst case-sensitive texts that every string matched by m
+// contains, in the order they appear in the pattern.
+func requiredLiterals(m match.Matcher, dst []string) []string {
+	switch m := m.(type) {
+	case match.Text:
+		if !m.Fold {
+			return appendLiteral(dst, m.Str)
+		}
+	case match.PrefixAny:
+		if !m.Fold {
+			return appendLiteral(dst, m.Prefix)
+		}
+	case match.SuffixAny:
+		if !m.Fold {
+			return appendLiteral(dst, m.Suffix)
+		}
+	case match.PrefixSuffix:
+		if !m.Fold {
+			return appendLiteral(appendLiteral(dst, m.Prefix), m.Suffix)
+		}
+	case match.Contains:
+		if !m.Fold {
+			return appendLiteral(dst, m.Needle)
+		}
+	case match.Substrings:
+		if !m.Fold {
+			for _, str := range m.Strs {
+				dst = appendLiteral(dst, str)
+			}
+		}
+	case match.DFA:
+		dst = requiredLiterals(m.Fallback, dst)
+	case match.LengthLimit:
+		dst = requiredLiterals(m.Matcher, dst)
+	case match.Row:
+		for _, c := range m.Matchers {
+			dst = requiredLiterals(c, dst)
+		}
+	case match.AnyOf:
+		if len(m.Matchers) == 1 {
+			dst = requiredLiterals(m.Matchers[0], dst)
+		}
+	case match.BTree:
+		if m.Left != nil {
+			dst = requiredLiterals(m.Left, dst)
+		}
+		dst = requiredLiterals(m.Value, dst)
+		if m.Right != nil {
+			dst = requiredLiterals(m.Right, dst)
+		}
+	}
+	return dst
+}
+
+func appendLiteral(dst []string, s string) []string {
+	if s == "" {
+		return dst
+	}
+	return append(dst, s)
+}
+
+// requiredLiteral returns the longest of required literals of m,
+// or an empty string if there are none.
+func requiredLiteral(m match.Matcher) (lit string) {
+	for _, s := range requiredLiterals(m, nil) {
+		if len(s) > len(lit) {
+			lit = s
+		}
+	}
+	return lit
+}
@@ -0,0 +1,64 @@
+package glob
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLiteralPrefix(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		prefix     string
+		complete   bool
+	}{
+		{pattern: "abc", prefix: "abc", complete: true},
+		{pattern: "", prefix: "", complete: true},
+		{pattern: "api.*", delimiters: []rune{'.'}, prefix: "api."},
+		{pattern: "*.com", prefix: ""},
+		{pattern: "src/*/*.go", delimiters: []rune{'/'}, prefix: "src/"},
+		{pattern: "ab*ef", prefix: "ab"},
+		{pattern: "ab?d*", features: Single, prefix: "ab"},
+		{pattern: "a[bc]d", features: Classes, prefix: "a"},
+		{pattern: `a\*b*`, features: Escape, prefix: "a*b"},
+		{pattern: "{api,apx}.*", features: Alternates, prefix: "ap"},
+		{pattern: "{api,web}.*", features: Alternates, prefix: ""},
+		{pattern: "x{abc,abd}y", features: Alternates, prefix: "xab"},
+		{pattern: "x{abc,abc}y", features: Alternates, prefix: "xabcy", complete: true},
+		{pattern: "abc*", features: CaseFold, prefix: ""},
+		{pattern: "abc", features: CaseFold, prefix: ""},
+	} {
+		g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+		prefix, complete := g.LiteralPrefix()
+		if prefix != test.prefix || complete != test.complete {
+			t.Errorf("pattern %q: unexpected literal prefix: exp: %q %v, act: %q %v\n%s", test.pattern, test.prefix, test.complete, prefix, complete, g)
+		}
+	}
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		literals   []string
+	}{
+		{pattern: "abc", literals: []string{"abc"}},
+		{pattern: "*", literals: nil},
+		{pattern: "*.example.com", literals: []string{".example.com"}},
+		{pattern: "a*bcd*ef", literals: []string{"a", "bcd", "ef"}},
+		{pattern: "a*b*c*d", delimiters: []rune{'.'}, literals: []string{"a", "b", "c", "d"}},
+		{pattern: "a?c", features: Single, literals: []string{"a", "c"}},
+		{pattern: "*abc*", literals: []string{"abc"}},
+		{pattern: "*ab*cd*", literals: []string{"ab", "cd"}},
+		{pattern: "x{abc,abd}y", features: Alternates, literals: []string{"x", "y"}},
+		{pattern: "{api,web}.com", features: Alternates, literals: []string{".com"}},
+		{pattern: "abc", features: CaseFold, literals: nil},
+	} {
+		g := MustCompileFeatures(test.pattern, test.features, test.delimiters...)
+		if act := g.RequiredLiterals(); !reflect.DeepEqual(act, test.literals) {
+			t.Errorf("pattern %q: unexpected literals: exp: %q, act: %q\n%s", test.pattern, test.literals, act, g)
+		}
+	}
+}
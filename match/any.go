@@ -44,3 +44,14 @@ func (a Any) Len() int {
 func (a Any) String() string {
 	return fmt.Sprintf("<any:![%s]>", string(a.Separators))
 }
+
+func (a Any) Capture(s string, dst []string) ([]string, bool) {
+	if !a.Match(s) {
+		return dst, false
+	}
+	return append(dst, s), true
+}
+
+func (a Any) CouldMatchPrefix(s string) bool {
+	return a.Match(s)
+}
@@ -0,0 +1,90 @@
+package match
+
+import (
+	"fmt"
+)
+
+// AnyOf represents any of the given matchers.
+type AnyOf struct {
+	Matchers Matchers
+}
+
+func NewAnyOf(m ...Matcher) AnyOf {
+	return AnyOf{Matchers(m)}
+}
+
+func (a AnyOf) Match(s string) bool {
+	for _, m := range a.Matchers {
+		if m.Match(s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a AnyOf) Index(s string) (int, []int) {
+	index := -1
+
+	segments := acquireSegments(len(s))
+	for _, m := range a.Matchers {
+		idx, seg := m.Index(s)
+		switch {
+		case idx == -1 || (index != -1 && idx > index):
+			// not better than found one
+
+		case index == -1 || idx < index:
+			index = idx
+			segments = append(segments[:0], seg...)
+
+		default:
+			// here idx == index
+			segments = appendMerge(segments, seg)
+		}
+		releaseSegments(seg)
+	}
+
+	if index == -1 {
+		releaseSegments(segments)
+		return -1, nil
+	}
+
+	return index, segments
+}
+
+func (a AnyOf) Len() int {
+	l := lenNo
+	for i, m := range a.Matchers {
+		ml := m.Len()
+		switch {
+		case ml == lenNo:
+			return lenNo
+		case i == 0:
+			l = ml
+		case l != ml:
+			return lenNo
+		}
+	}
+
+	return l
+}
+
+func (a AnyOf) String() string {
+	return fmt.Sprintf("<any_of:[%s]>", a.Matchers)
+}
+
+func (a AnyOf) Capture(s string, dst []string) ([]string, bool) {
+	if !a.Match(s) {
+		return dst, false
+	}
+	return append(dst, s), true
+}
+
+func (a AnyOf) CouldMatchPrefix(s string) bool {
+	for _, m := range a.Matchers {
+		if m.CouldMatchPrefix(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,100 @@
+package match
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnyOfIndex(t *testing.T) {
+	for id, test := range []struct {
+		matchers Matchers
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{
+			Matchers{
+				NewAny(nil),
+				NewText("b"),
+				NewText("c"),
+			},
+			"abc",
+			0,
+			[]int{0, 1, 2, 3},
+		},
+		{
+			Matchers{
+				NewPrefixAny("b", nil),
+				NewText("c"),
+			},
+			"abc",
+			1,
+			[]int{1, 2},
+		},
+		{
+			Matchers{
+				NewText("bc"),
+				NewText("b"),
+			},
+			"abcd",
+			1,
+			[]int{1, 2},
+		},
+		{
+			Matchers{
+				NewText("x"),
+				NewText("y"),
+			},
+			"abc",
+			-1,
+			nil,
+		},
+	} {
+		a := NewAnyOf(test.matchers...)
+		index, segments := a.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
+
+func TestAnyOfIndexReleased(t *testing.T) {
+	// Merged segments are taken from the pool; make sure returned ones
+	// are not given back to it and overwritten by subsequent calls.
+	fixture := "ab" + strings.Repeat("x", 30)
+	a := NewAnyOf(NewPrefixAny("a", nil), NewPrefixAny("ab", nil))
+	index, segments := a.Index(fixture)
+	if index != 0 {
+		t.Fatalf("unexpected index: exp: %d, act: %d", 0, index)
+	}
+	exp := append([]int(nil), segments...)
+	for i := 0; i < 10; i++ {
+		NewAny(nil).Index(fixture)
+	}
+	if !reflect.DeepEqual(segments, exp) {
+		t.Errorf("unexpected segments after other calls: exp: %v, act: %v", exp, segments)
+	}
+	if len(exp) != len(fixture) {
+		t.Errorf("unexpected segments count: exp: %d, act: %d", len(fixture), len(exp))
+	}
+}
+
+func TestAnyOfLen(t *testing.T) {
+	for id, test := range []struct {
+		matchers Matchers
+		exp      int
+	}{
+		{Matchers{NewText("ab"), NewText("cd")}, 2},
+		{Matchers{NewText("ab"), NewText("c")}, -1},
+		{Matchers{NewAny(nil), NewText("cd")}, -1},
+		{Matchers{NewText("cd"), NewAny(nil)}, -1},
+	} {
+		if act := NewAnyOf(test.matchers...).Len(); act != test.exp {
+			t.Errorf("#%d unexpected len: exp: %d, act: %d", id, test.exp, act)
+		}
+	}
+}
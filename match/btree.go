@@ -2,6 +2,7 @@ package match
 
 import (
 	"fmt"
+	"sort"
 	"unicode/utf8"
 )
 
@@ -50,55 +51,161 @@ func (t BTree) Len() int {
 	return t.LengthRunes
 }
 
-// Index todo?
-func (t BTree) Index(s string) (index int, segments []int) {
-	//inputLen := len(s)
-	//// try to cut unnecessary parts
-	//// by knowledge of length of right and left part
-	//offset, limit := t.offsetLimit(inputLen)
-	//for offset < limit {
-	//	// search for matching part in substring
-	//	vi, segments := t.Value.Index(s[offset:limit])
-	//	if index == -1 {
-	//		return -1, nil
-	//	}
-	//	if t.Left == nil {
-	//		if index != offset {
-	//			return -1, nil
-	//		}
-	//	} else {
-	//		left := s[:offset+vi]
-	//		i := t.Left.IndexSuffix(left)
-	//		if i == -1 {
-	//			return -1, nil
-	//		}
-	//		index = i
-	//	}
-	//	if t.Right != nil {
-	//		for _, seg := range segments {
-	//			right := s[:offset+vi+seg]
-	//		}
-	//	}
-
-	//	l := s[:offset+index]
-	//	var left bool
-	//	if t.Left != nil {
-	//		left = t.Left.Index(l)
-	//	} else {
-	//		left = l == ""
-	//	}
-	//}
+// Index returns the leftmost index of the tree match in s
+// and the lengths of all matches starting at that index.
+//
+// If the left part has fixed length, the start of a match is determined by
+// the start of the value, so occurrences of the value are searched only once.
+// Otherwise every start offset is tried, which could take super-linear time;
+// the compiler searches with DFA for such trees.
+func (t BTree) Index(s string) (int, []int) {
+	if t.LengthRunes != -1 && t.LengthRunes > len(s) {
+		return -1, nil
+	}
+
+	if t.LeftLengthRunes != -1 {
+		return t.indexFixedLeft(s)
+	}
+
+	for start := 0; start <= len(s); {
+		if segments := t.lengthsFrom(s, start); len(segments) > 0 {
+			return start, segments
+		}
+
+		if start == len(s) {
+			break
+		}
+		_, step := utf8.DecodeRuneInString(s[start:])
+		start += step
+	}
 
 	return -1, nil
 }
 
+// indexFixedLeft is Index for trees without left part or with fixed length one.
+// Starts of matches grow with starts of the value, so the first occurrence
+// of the value that completes a match gives the leftmost match.
+func (t BTree) indexFixedLeft(s string) (int, []int) {
+	// each rune takes at least one byte
+	for offset := t.LeftLengthRunes; offset <= len(s); {
+		index, segments := t.Value.Index(s[offset:])
+		if index == -1 {
+			releaseSegments(segments)
+			return -1, nil
+		}
+		valueStart := offset + index
+
+		if start := runesBefore(s, valueStart, t.LeftLengthRunes); start != -1 && matchPart(t.Left, s[start:valueStart]) {
+			lengths := t.appendLengths(nil, s, start, valueStart, segments)
+			if len(lengths) > 0 {
+				releaseSegments(segments)
+				return start, sortedSegments(lengths)
+			}
+		}
+		releaseSegments(segments)
+
+		if valueStart == len(s) {
+			break
+		}
+		_, step := utf8.DecodeRuneInString(s[valueStart:])
+		offset = valueStart + step
+	}
+
+	return -1, nil
+}
+
+// runesBefore returns the offset n runes before the end offset of s,
+// or -1 if there are less than n runes before it.
+func runesBefore(s string, end, n int) int {
+	for ; n > 0; n-- {
+		if end == 0 {
+			return -1
+		}
+		_, w := utf8.DecodeLastRuneInString(s[:end])
+		end -= w
+	}
+	return end
+}
+
+// lengthsFrom returns sorted lengths of all matches of the tree
+// that begin at the start offset of s.
+func (t BTree) lengthsFrom(s string, start int) []int {
+	var lengths []int
+	offset := start
+	if t.LeftLengthRunes > 0 {
+		// each rune takes at least one byte
+		offset += t.LeftLengthRunes
+	}
+
+	for offset <= len(s) {
+		index, segments := t.Value.Index(s[offset:])
+		if index == -1 {
+			releaseSegments(segments)
+			break
+		}
+		valueStart := offset + index
+
+		if matchPart(t.Left, s[start:valueStart]) {
+			lengths = t.appendLengths(lengths, s, start, valueStart, segments)
+		}
+		releaseSegments(segments)
+
+		if t.Left == nil || valueStart == len(s) {
+			break
+		}
+		_, step := utf8.DecodeRuneInString(s[valueStart:])
+		offset = valueStart + step
+	}
+
+	if len(lengths) == 0 {
+		return nil
+	}
+
+	return sortedSegments(lengths)
+}
+
+// appendLengths appends to lengths the lengths of matches that begin at the
+// start offset of s and have the value of given lengths at the valueStart offset.
+func (t BTree) appendLengths(lengths []int, s string, start, valueStart int, segments []int) []int {
+	for _, length := range segments {
+		valueEnd := valueStart + length
+		if t.Right == nil {
+			lengths = append(lengths, valueEnd-start)
+			continue
+		}
+
+		// right part must begin exactly at the end of the value
+		i, rs := t.Right.Index(s[valueEnd:])
+		if i == 0 {
+			for _, l := range rs {
+				lengths = append(lengths, valueEnd+l-start)
+			}
+		}
+		releaseSegments(rs)
+	}
+	return lengths
+}
+
+// sortedSegments returns sorted unique lengths as segments.
+func sortedSegments(lengths []int) []int {
+	sort.Ints(lengths)
+	segments := acquireSegments(len(lengths))
+	for i, l := range lengths {
+		if i == 0 || l != lengths[i-1] {
+			segments = append(segments, l)
+		}
+	}
+	return segments
+}
+
 func (t BTree) Match(s string) bool {
 	inputLen := len(s)
 	// try to cut unnecessary parts
 	// by knowledge of length of right and left part
 	offset, limit := t.offsetLimit(inputLen)
 
-	for offset < limit {
+	// offset == limit is tried too, as the value could match an empty string
+	for offset <= limit {
 		// search for matching part in substring
 		index, segments := t.Value.Index(s[offset:limit])
 		if index == -1 {
@@ -140,21 +247,90 @@ func (t BTree) Match(s string) bool {
 			}
 		}
 
+		releaseSegments(segments)
+
+		if offset+index >= limit {
+			break
+		}
 		_, step := utf8.DecodeRuneInString(s[offset+index:])
 		offset += index + step
+	}
+
+	return false
+}
 
+// Capture appends texts consumed by wildcards of the tree to dst.
+// When s could be split between parts of the tree in several ways,
+// the split with the longest left part, and then the longest value, is used.
+func (t BTree) Capture(s string, dst []string) ([]string, bool) {
+	type split struct {
+		start, end int
+	}
+
+	var splits []split
+	offset, limit := t.offsetLimit(len(s))
+	for offset <= limit {
+		index, segments := t.Value.Index(s[offset:limit])
+		if index == -1 {
+			releaseSegments(segments)
+			break
+		}
+		for _, length := range segments {
+			splits = append(splits, split{offset + index, offset + index + length})
+		}
 		releaseSegments(segments)
+
+		if offset+index >= limit {
+			break
+		}
+		_, step := utf8.DecodeRuneInString(s[offset+index:])
+		offset += index + step
 	}
 
-	return false
+	// splits are ordered by value position and then by value length
+	for i := len(splits) - 1; i >= 0; i-- {
+		sp := splits[i]
+		if !matchPart(t.Left, s[:sp.start]) || !matchPart(t.Right, s[sp.end:]) {
+			continue
+		}
+
+		n := len(dst)
+		var ok bool
+		if dst, ok = capturePart(t.Left, s[:sp.start], dst); ok {
+			if dst, ok = Capture(t.Value, s[sp.start:sp.end], dst); ok {
+				if dst, ok = capturePart(t.Right, s[sp.end:], dst); ok {
+					return dst, true
+				}
+			}
+		}
+		dst = dst[:n]
+	}
+
+	return dst, false
 }
 
+func matchPart(m Matcher, s string) bool {
+	if m == nil {
+		return s == ""
+	}
+	return m.Match(s)
+}
+
+func capturePart(m Matcher, s string, dst []string) ([]string, bool) {
+	if m == nil {
+		return dst, s == ""
+	}
+	return Capture(m, s, dst)
+}
+
+// offsetLimit returns the range of offsets in the input the value could begin at.
+// The range is empty, that is offset > limit, if the tree could not match.
 func (t BTree) offsetLimit(inputLen int) (offset int, limit int) {
 	// t.Length, t.RLen and t.LLen are values meaning the length of runes for each part
 	// here we manipulating byte length for better optimizations
 	// but these checks still works, cause minLen of 1-rune string is 1 byte.
 	if t.LengthRunes != -1 && t.LengthRunes > inputLen {
-		return 0, 0
+		return 0, -1
 	}
 	if t.LeftLengthRunes >= 0 {
 		offset = t.LeftLengthRunes
@@ -183,3 +359,52 @@ func (t BTree) String() string {
 
 	return fmt.Sprintf("<btree:[%s<-%s->%s]>", l, t.Value, r)
 }
+
+func (t BTree) CouldMatchPrefix(s string) bool {
+	// s ends inside of the left part
+	if t.Left != nil && t.Left.CouldMatchPrefix(s) {
+		return true
+	}
+
+	for start := 0; start <= len(s); {
+		if matchPart(t.Left, s[:start]) {
+			// s ends inside of the value
+			if t.Value.CouldMatchPrefix(s[start:]) {
+				return true
+			}
+			// value ends inside of s
+			if t.couldMatchRight(s, start) {
+				return true
+			}
+		}
+
+		if t.Left == nil || start == len(s) {
+			break
+		}
+		_, w := utf8.DecodeRuneInString(s[start:])
+		start += w
+	}
+
+	return false
+}
+
+// couldMatchRight reports whether the value matches s beginning at start
+// and the right part could match the rest of s.
+func (t BTree) couldMatchRight(s string, start int) bool {
+	index, segments := t.Value.Index(s[start:])
+	defer releaseSegments(segments)
+	if index != 0 {
+		return false
+	}
+
+	for _, length := range segments {
+		end := start + length
+		if t.Right == nil && end == len(s) {
+			return true
+		}
+		if t.Right != nil && t.Right.CouldMatchPrefix(s[end:]) {
+			return true
+		}
+	}
+	return false
+}
@@ -1,6 +1,7 @@
 package match
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -20,7 +21,31 @@ func TestBTree(t *testing.T) {
 			"aaa",
 			true,
 		},
+		{
+			NewBTree(NewAnyOf(NewText("a"), NewNothing()), NewAny(nil), nil),
+			"",
+			true,
+		},
+		{
+			NewBTree(NewAnyOf(NewText("a"), NewNothing()), NewText("x"), NewText("y")),
+			"xy",
+			true,
+		},
+		{
+			NewBTree(NewAnyOf(NewText("a"), NewNothing()), NewText("x"), NewText("y")),
+			"xay",
+			true,
+		},
+		{
+			NewBTree(NewAnyOf(NewText("a"), NewNothing()), NewText("x"), NewText("y")),
+			"xby",
+			false,
+		},
 	} {
+		if _, ok := test.tree.Capture(test.str, nil); ok != test.exp {
+			t.Errorf("#%d capture %q error: act: %t; exp: %t", id, test.str, ok, test.exp)
+		}
+
 		act := test.tree.Match(test.str)
 		if act != test.exp {
 			t.Errorf("#%d match %q error: act: %t; exp: %t", id, test.str, act, test.exp)
@@ -29,6 +54,112 @@ func TestBTree(t *testing.T) {
 	}
 }
 
+func TestBTreeIndex(t *testing.T) {
+	for id, test := range []struct {
+		tree     BTree
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{
+			NewBTree(NewText("abc"), nil, nil),
+			"qweabcdef",
+			3,
+			[]int{3},
+		},
+		{
+			NewBTree(NewText("b"), NewAny([]rune{'.'}), nil),
+			"x.aab.b",
+			2,
+			[]int{3},
+		},
+		{
+			NewBTree(NewText("b"), NewAny(nil), NewAny([]rune{'.'})),
+			"aab.b",
+			0,
+			[]int{3, 5},
+		},
+		{
+			NewBTree(NewText("b"), nil, NewAny([]rune{'.'})),
+			"aabcd.e",
+			2,
+			[]int{1, 2, 3},
+		},
+		{
+			NewBTree(NewText("def"), NewPrefixAny("abc", nil), nil),
+			"xxabcdefdef",
+			2,
+			[]int{6, 9},
+		},
+		{
+			NewBTree(NewText("."), NewAny([]rune{'.'}), NewText("com")),
+			"a.b.com",
+			2,
+			[]int{5},
+		},
+		{
+			NewBTree(NewText("日"), NewAny(nil), NewText("本")),
+			"ab日本",
+			0,
+			[]int{8},
+		},
+		{
+			NewBTree(NewText("abc"), NewAny(nil), NewText("x")),
+			"abcabc",
+			-1,
+			nil,
+		},
+		{
+			NewBTree(NewText("b"), NewSingle(nil), NewAny(nil)),
+			"bxbb",
+			1,
+			[]int{2, 3},
+		},
+		{
+			NewBTree(NewText("b"), NewSingle(nil), NewText("c")),
+			"日bxb日bc",
+			6,
+			[]int{5},
+		},
+		{
+			NewBTree(NewText("b"), NewRow(2, NewSingle(nil), NewText("a")), nil),
+			"xbxab",
+			2,
+			[]int{3},
+		},
+	} {
+		index, segments := test.tree.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+
+		// compare with brute force search over all substrings
+		expIndex, expSegments := bruteForceIndex(test.tree, test.fixture)
+		if index != expIndex || !reflect.DeepEqual(segments, expSegments) {
+			t.Errorf("#%d index differs from brute force: exp: %d %v, act: %d %v", id, expIndex, expSegments, index, segments)
+		}
+	}
+}
+
+func bruteForceIndex(m Matcher, s string) (int, []int) {
+	// the trailing space makes range visit len(s) too
+	for start := range s + " " {
+		var segments []int
+		for length := range s[start:] + " " {
+			if m.Match(s[start : start+length]) {
+				segments = append(segments, length)
+			}
+		}
+		if len(segments) > 0 {
+			return start, segments
+		}
+	}
+	return -1, nil
+}
+
 type fakeMatcher struct {
 	len  int
 	name string
@@ -53,6 +184,9 @@ func (f *fakeMatcher) Len() int {
 func (f *fakeMatcher) String() string {
 	return f.name
 }
+func (f *fakeMatcher) CouldMatchPrefix(string) bool {
+	return true
+}
 
 func BenchmarkMatchBTree(b *testing.B) {
 	l := &fakeMatcher{4, "left_fake"}
@@ -70,3 +204,60 @@ func BenchmarkMatchBTree(b *testing.B) {
 		}
 	})
 }
+
+func TestBTreeCapture(t *testing.T) {
+	for id, test := range []struct {
+		tree     BTree
+		fixture  string
+		captures []string
+		ok       bool
+	}{
+		{
+			NewBTree(NewText("."), NewAny(nil), NewAny(nil)),
+			"a.b.c",
+			[]string{"a.b", "c"},
+			true,
+		},
+		{
+			NewBTree(NewText("."), NewAny([]rune{'/'}), NewSuffixAny(".com", nil)),
+			"www.example.com",
+			[]string{"www", "example"},
+			true,
+		},
+		{
+			NewBTree(NewText("abc"), NewAny(nil), nil),
+			"abcab",
+			nil,
+			false,
+		},
+	} {
+		captures, ok := test.tree.Capture(test.fixture, nil)
+		if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func TestBTreeCouldMatchPrefix(t *testing.T) {
+	for id, test := range []struct {
+		tree   BTree
+		prefix string
+		should bool
+	}{
+		{NewBTree(NewText("abc"), NewAny([]rune{'/'}), nil), "x/", false},
+		{NewBTree(NewText("abc"), NewAny([]rune{'/'}), nil), "xab", true},
+		{NewBTree(NewText("abc"), NewAny([]rune{'/'}), nil), "xabc", true},
+		{NewBTree(NewText("abc"), NewAny([]rune{'/'}), nil), "xabcd", true},
+		{NewBTree(NewText("abc"), NewAny([]rune{'/'}), nil), "xabc/", false},
+		{NewBTree(NewText("/"), NewText("src"), NewAny([]rune{'/'})), "sr", true},
+		{NewBTree(NewText("/"), NewText("src"), NewAny([]rune{'/'})), "src/main", true},
+		{NewBTree(NewText("/"), NewText("src"), NewAny([]rune{'/'})), "src/main/", false},
+		{NewBTree(NewText("/"), NewText("src"), NewAny([]rune{'/'})), "lib", false},
+		{NewBTree(NewText("/"), NewAny([]rune{'/'}), NewText("x")), "a/", true},
+		{NewBTree(NewText("/"), NewAny([]rune{'/'}), NewText("x")), "a/y", false},
+	} {
+		if act := test.tree.CouldMatchPrefix(test.prefix); act != test.should {
+			t.Errorf("#%d %s CouldMatchPrefix(%q) = %v; want %v", id, test.tree, test.prefix, act, test.should)
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package match
+
+import (
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+// Matchers never retain the matched string, so b could be passed to them
+// without copying it into a string.
+
+// MatchBytes reports whether b matches m, without allocating.
+func MatchBytes(m Matcher, b []byte) bool {
+	return m.Match(sutil.FromBytes(b))
+}
+
+// IndexBytes is the same as m.Index for b, without copying it.
+func IndexBytes(m Matcher, b []byte) (int, []int) {
+	return m.Index(sutil.FromBytes(b))
+}
@@ -0,0 +1,48 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchBytes(t *testing.T) {
+	sep := []rune{'.'}
+	for id, m := range []Matcher{
+		NewText("abc"),
+		NewAny(sep),
+		NewSuper(),
+		NewPrefixAny("abc", sep),
+		NewSuffixAny("def", sep),
+		NewPrefixSuffix("abc", "xyz"),
+		NewContains("mno"),
+		NewSubstrings("ab", "mn", "yz"),
+		NewBTree(NewText("mno"), NewAny(sep), NewAny(sep)),
+	} {
+		for _, fixture := range []string{"", "abc", bench_pattern, "abc.def"} {
+			b := []byte(fixture)
+			if act, exp := MatchBytes(m, b), m.Match(fixture); act != exp {
+				t.Errorf("#%d %s: unexpected match of %q: exp: %v, act: %v", id, m, fixture, exp, act)
+			}
+
+			index, segments := IndexBytes(m, b)
+			expIndex, expSegments := m.Index(fixture)
+			if index != expIndex || !reflect.DeepEqual(segments, expSegments) {
+				t.Errorf("#%d %s: unexpected index of %q: exp: %d %v, act: %d %v", id, m, fixture, expIndex, expSegments, index, segments)
+			}
+		}
+
+		b := []byte(bench_pattern)
+		if n := testing.AllocsPerRun(10, func() { MatchBytes(m, b) }); n != 0 {
+			t.Errorf("#%d %s: unexpected allocations: %v", id, m, n)
+		}
+	}
+}
+
+func BenchmarkMatchBytesBTree(b *testing.B) {
+	m := NewBTree(NewText("mno"), NewAny(bench_separators), NewAny(bench_separators))
+	fixture := []byte(bench_pattern)
+
+	for i := 0; i < b.N; i++ {
+		MatchBytes(m, fixture)
+	}
+}
@@ -0,0 +1,89 @@
+package match
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/util/runes"
+)
+
+// Class represents single character matched by any of the Items (or by none of them if Not is set),
+// such as `[a-z0-9_]` that is a union of two ranges and a list.
+// Separators are never matched by negated class.
+type Class struct {
+	Items      []RuneMatcher
+	Not        bool
+	Separators []rune
+}
+
+func NewClass(items []RuneMatcher, not bool, sep []rune) Class {
+	return Class{Items: items, Not: not, Separators: sep}
+}
+
+func (c Class) contains(r rune) bool {
+	for _, item := range c.Items {
+		if item.MatchRune(r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Class) Match(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	r, w := utf8.DecodeRuneInString(s)
+	if len(s) > w {
+		return false
+	}
+
+	return c.MatchRune(r)
+}
+
+func (c Class) MatchRune(r rune) bool {
+	inClass := c.contains(r)
+	if !c.Not {
+		return inClass
+	}
+
+	return !inClass && runes.IndexRune(c.Separators, r) == -1
+}
+
+func (c Class) Len() int {
+	return lenOne
+}
+
+func (c Class) Index(s string) (int, []int) {
+	for i := 0; i < len(s); {
+		// width of invalid encoding is 1, while RuneLen(RuneError) is 3
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if c.MatchRune(r) {
+			return i, segmentsByRuneLength[w]
+		}
+		i += w
+	}
+
+	return -1, nil
+}
+
+func (c Class) String() string {
+	var not string
+	if c.Not {
+		not = "!"
+	}
+
+	return fmt.Sprintf("<class:%s%v>", not, c.Items)
+}
+
+func (c Class) Capture(s string, dst []string) ([]string, bool) {
+	if !c.Match(s) {
+		return dst, false
+	}
+	return append(dst, s), true
+}
+
+func (c Class) CouldMatchPrefix(s string) bool {
+	return len(s) == 0 || c.Match(s)
+}
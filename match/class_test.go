@@ -0,0 +1,80 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassIndex(t *testing.T) {
+	alnum := []RuneMatcher{
+		NewRange('a', 'z', false, nil),
+		NewRange('0', '9', false, nil),
+		NewList([]rune{'-', '_'}, false),
+	}
+	for id, test := range []struct {
+		items      []RuneMatcher
+		not        bool
+		separators []rune
+		fixture    string
+		index      int
+		segments   []int
+	}{
+		{
+			alnum,
+			false,
+			nil,
+			"ABC7",
+			3,
+			[]int{1},
+		},
+		{
+			alnum,
+			false,
+			nil,
+			"AB_C",
+			2,
+			[]int{1},
+		},
+		{
+			alnum,
+			true,
+			nil,
+			"ab-9.",
+			4,
+			[]int{1},
+		},
+		{
+			alnum,
+			true,
+			[]rune{'.'},
+			"ab-9.",
+			-1,
+			nil,
+		},
+		{
+			[]RuneMatcher{NewRange('日', '語', false, nil), NewList([]rune{'a'}, false)},
+			false,
+			nil,
+			"xy本",
+			2,
+			[]int{3},
+		},
+		{
+			alnum,
+			true,
+			nil,
+			"a\xff",
+			1,
+			[]int{1},
+		},
+	} {
+		c := NewClass(test.items, test.not, test.separators)
+		index, segments := c.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+// Contains represents any string that contains Needle,
+// that is Needle surrounded by any sequences of characters.
+// If Fold is set, the needle is compared under Unicode simple case folding.
+type Contains struct {
+	Needle string
+	Fold   bool
+}
+
+func NewContains(needle string) Contains {
+	return Contains{Needle: needle}
+}
+
+func (c Contains) Match(s string) bool {
+	idx, _ := c.index(s)
+	return idx != -1
+}
+
+// Index returns 0 and lengths of all prefixes of s that contain the needle.
+func (c Contains) Index(s string) (int, []int) {
+	idx, n := c.index(s)
+	if idx == -1 {
+		return -1, nil
+	}
+	return 0, appendRuneEnds(acquireSegments(len(s)-idx-n+1), s, idx+n)
+}
+
+// index returns the index of the first needle instance in s and its length.
+func (c Contains) index(s string) (int, int) {
+	if c.Fold {
+		return sutil.IndexFold(s, c.Needle)
+	}
+	return strings.Index(s, c.Needle), len(c.Needle)
+}
+
+// lastIndex returns the index of the last needle instance in s and its length.
+func (c Contains) lastIndex(s string) (int, int) {
+	if c.Fold {
+		return sutil.LastIndexFold(s, c.Needle)
+	}
+	return strings.LastIndex(s, c.Needle), len(c.Needle)
+}
+
+func (c Contains) Len() int {
+	return lenNo
+}
+
+func (c Contains) String() string {
+	return fmt.Sprintf("<contains:[%s]%s>", c.Needle, foldFlag(c.Fold))
+}
+
+// Capture appends texts before and after the last needle instance in s.
+func (c Contains) Capture(s string, dst []string) ([]string, bool) {
+	idx, n := c.lastIndex(s)
+	if idx == -1 {
+		return dst, false
+	}
+	return append(dst, s[:idx], s[idx+n:]), true
+}
+
+func (c Contains) CouldMatchPrefix(string) bool {
+	return true
+}
+
+// appendRuneEnds appends to segments offset from and the end of each rune of s after it.
+func appendRuneEnds(segments []int, s string, from int) []int {
+	segments = append(segments, from)
+	for i := from; i < len(s); {
+		_, w := utf8.DecodeRuneInString(s[i:])
+		i += w
+		segments = append(segments, i)
+	}
+	return segments
+}
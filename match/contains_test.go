@@ -0,0 +1,61 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestContainsIndex(t *testing.T) {
+	for id, test := range []struct {
+		needle   string
+		fold     bool
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{"ab", false, "ab", 0, []int{2}},
+		{"ab", false, "xabyä", 0, []int{3, 4, 6}},
+		{"ab", false, "xa", -1, nil},
+		{"AB", true, "xab", 0, []int{3}},
+	} {
+		m := NewContains(test.needle)
+		m.Fold = test.fold
+
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+		if act := m.Match(test.fixture); act != (test.index != -1) {
+			t.Errorf("#%d unexpected match: %v", id, act)
+		}
+	}
+}
+
+func TestContainsCapture(t *testing.T) {
+	for id, test := range []struct {
+		needle   string
+		fixture  string
+		captures []string
+		ok       bool
+	}{
+		{"b", "abcbd", []string{"abc", "d"}, true},
+		{"b", "b", []string{"", ""}, true},
+		{"b", "acd", nil, false},
+	} {
+		captures, ok := NewContains(test.needle).Capture(test.fixture, nil)
+		if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func BenchmarkMatchContains(b *testing.B) {
+	m := NewContains("qew")
+
+	for i := 0; i < b.N; i++ {
+		m.Match(bench_pattern)
+	}
+}
@@ -16,6 +16,10 @@ func graphvizInternal(m match.Matcher, id string) string {
 	buf := &bytes.Buffer{}
 
 	switch matcher := m.(type) {
+	case match.DFA:
+		return graphvizInternal(matcher.Fallback, id)
+	case match.LengthLimit:
+		return graphvizInternal(matcher.Matcher, id)
 	case match.BTree:
 		_, _ = fmt.Fprintf(buf, `"%s"[label="%s"];`, id, matcher.Value.String())
 		for _, m := range []match.Matcher{matcher.Left, matcher.Right} {
@@ -0,0 +1,314 @@
+package match
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// RuneMatcher is implemented by matchers of exactly one character.
+type RuneMatcher interface {
+	MatchRune(rune) bool
+}
+
+const (
+	nfaMatch = iota
+	nfaRune
+	nfaSplit
+)
+
+type nfaState struct {
+	kind      uint8
+	class     RuneMatcher
+	out, out1 int
+}
+
+// NFA is a nondeterministic finite automaton over runes.
+// It is built backwards: each added state is given the states it leads to,
+// starting from the accepting state returned by Match.
+type NFA struct {
+	states []nfaState
+}
+
+func NewNFA() *NFA {
+	return &NFA{
+		states: []nfaState{{kind: nfaMatch}},
+	}
+}
+
+// Match returns the accepting state.
+func (n *NFA) Match() int {
+	return 0
+}
+
+// Rune adds state consuming one character matched by class and leading to next.
+func (n *NFA) Rune(class RuneMatcher, next int) int {
+	n.states = append(n.states, nfaState{kind: nfaRune, class: class, out: next})
+	return len(n.states) - 1
+}
+
+// Split adds state leading to both out and out1 without consuming a character.
+func (n *NFA) Split(out, out1 int) int {
+	n.states = append(n.states, nfaState{kind: nfaSplit, out: out, out1: out1})
+	return len(n.states) - 1
+}
+
+// Star adds states consuming any number of characters matched by class and leading to next.
+func (n *NFA) Star(class RuneMatcher, next int) int {
+	s := n.Split(-1, next)
+	n.states[s].out = n.Rune(class, s)
+	return s
+}
+
+// dfaMaxStates is the maximum number of cached states of a DFA.
+// When it is reached, the cache is flushed and states are built again.
+const dfaMaxStates = 1024
+
+// DFA matches strings with deterministic automaton lazily built from NFA,
+// so the time of Match and Index is linear in the length of the string.
+// Capture is delegated to Fallback matcher that is equivalent to the NFA.
+type DFA struct {
+	nfa      *NFA
+	caches   *sync.Pool
+	reverse  *sync.Pool
+	Fallback Matcher
+}
+
+// NewDFA creates DFA from the states of nfa. The start state begins states
+// matching strings of the pattern, and the reverse state begins states
+// matching reversed strings of the pattern followed by any string.
+func NewDFA(nfa *NFA, start, reverse int, fallback Matcher) DFA {
+	return DFA{
+		nfa:      nfa,
+		caches:   newDFACachePool(nfa, start),
+		reverse:  newDFACachePool(nfa, reverse),
+		Fallback: fallback,
+	}
+}
+
+func newDFACachePool(nfa *NFA, start int) *sync.Pool {
+	return &sync.Pool{
+		New: func() interface{} {
+			return newDFACache(nfa, start)
+		},
+	}
+}
+
+// run runs the automaton over s. It reports whether s is accepted
+// and whether the automaton is still alive, so s could be continued.
+func (d DFA) run(s string) (match, alive bool) {
+	c := d.caches.Get().(*dfaCache)
+	defer d.caches.Put(c)
+
+	state := c.startState()
+	for _, r := range s {
+		if state = c.next(state, r); len(state.nfa) == 0 {
+			return false, false
+		}
+	}
+	return state.match, true
+}
+
+func (d DFA) Match(s string) bool {
+	match, _ := d.run(s)
+	return match
+}
+
+func (d DFA) CouldMatchPrefix(s string) bool {
+	_, alive := d.run(s)
+	return alive
+}
+
+// Index runs the reversed automaton from the end of s to find the leftmost
+// position a match begins at, then runs the automaton from that position to
+// find lengths of all matches beginning there.
+func (d DFA) Index(s string) (int, []int) {
+	c := d.reverse.Get().(*dfaCache)
+	index := -1
+	state := c.startState()
+	if state.match {
+		index = len(s)
+	}
+	for i := len(s); i > 0 && len(state.nfa) != 0; {
+		r, w := utf8.DecodeLastRuneInString(s[:i])
+		i -= w
+		if state = c.next(state, r); state.match {
+			index = i
+		}
+	}
+	d.reverse.Put(c)
+
+	if index == -1 {
+		return -1, nil
+	}
+
+	c = d.caches.Get().(*dfaCache)
+	defer d.caches.Put(c)
+
+	segments := acquireSegments(len(s) - index + 1)
+	state = c.startState()
+	if state.match {
+		segments = append(segments, 0)
+	}
+	for i := index; i < len(s); {
+		r, w := utf8.DecodeRuneInString(s[i:])
+		i += w
+		if state = c.next(state, r); len(state.nfa) == 0 {
+			break
+		}
+		if state.match {
+			segments = append(segments, i-index)
+		}
+	}
+
+	return index, segments
+}
+
+func (d DFA) Len() int {
+	return d.Fallback.Len()
+}
+
+func (d DFA) String() string {
+	return fmt.Sprintf("<dfa:%s>", d.Fallback)
+}
+
+func (d DFA) Capture(s string, dst []string) ([]string, bool) {
+	return Capture(d.Fallback, s, dst)
+}
+
+type dfaState struct {
+	// sorted rune consuming and accepting states of the NFA
+	nfa   []int
+	match bool
+	ascii [utf8.RuneSelf]*dfaState
+	other map[rune]*dfaState
+}
+
+// dfaCache holds states of a DFA built so far. It is not safe for concurrent use.
+type dfaCache struct {
+	nfa    *NFA
+	start  int
+	states map[string]*dfaState
+	first  *dfaState
+
+	// buffers reused to compute the next state
+	seen  []bool
+	stack []int
+	set   []int
+	key   []byte
+}
+
+func newDFACache(nfa *NFA, start int) *dfaCache {
+	return &dfaCache{
+		nfa:    nfa,
+		start:  start,
+		states: make(map[string]*dfaState),
+		seen:   make([]bool, len(nfa.states)),
+	}
+}
+
+func (c *dfaCache) startState() *dfaState {
+	if c.first == nil {
+		c.set = c.closure(c.set[:0], c.start)
+		c.first = c.state(c.set)
+	}
+	return c.first
+}
+
+// next returns the state the automaton moves to from state on r.
+func (c *dfaCache) next(state *dfaState, r rune) *dfaState {
+	if r >= 0 && r < utf8.RuneSelf {
+		if next := state.ascii[r]; next != nil {
+			return next
+		}
+	} else if next, ok := state.other[r]; ok {
+		return next
+	}
+
+	c.set = c.set[:0]
+	for _, i := range state.nfa {
+		if s := c.nfa.states[i]; s.kind == nfaRune && s.class.MatchRune(r) {
+			c.set = c.closure(c.set, s.out)
+		}
+	}
+
+	if len(c.states) >= dfaMaxStates {
+		// given state is not reachable from the cache anymore,
+		// so it is safe to keep caching transitions in it
+		c.states = make(map[string]*dfaState)
+		c.first = nil
+	}
+
+	next := c.state(c.set)
+	if r >= 0 && r < utf8.RuneSelf {
+		state.ascii[r] = next
+	} else {
+		if state.other == nil {
+			state.other = make(map[rune]*dfaState)
+		}
+		state.other[r] = next
+	}
+
+	return next
+}
+
+// closure appends to set the states reachable from i without consuming a character.
+// Appended states are marked in c.seen until the set is passed to c.state.
+func (c *dfaCache) closure(set []int, i int) []int {
+	c.stack = append(c.stack[:0], i)
+	for len(c.stack) > 0 {
+		i := c.stack[len(c.stack)-1]
+		c.stack = c.stack[:len(c.stack)-1]
+		if c.seen[i] {
+			continue
+		}
+		c.seen[i] = true
+		set = append(set, i)
+
+		if s := c.nfa.states[i]; s.kind == nfaSplit {
+			c.stack = append(c.stack, s.out1, s.out)
+		}
+	}
+	return set
+}
+
+// state returns cached state for the set of NFA states, creating it if needed.
+func (c *dfaCache) state(set []int) *dfaState {
+	for _, i := range set {
+		c.seen[i] = false
+	}
+
+	// keep only rune consuming and accepting states
+	n := 0
+	for _, i := range set {
+		if c.nfa.states[i].kind != nfaSplit {
+			set[n] = i
+			n++
+		}
+	}
+	set = set[:n]
+	sort.Ints(set)
+
+	c.key = c.key[:0]
+	var buf [binary.MaxVarintLen64]byte
+	for _, i := range set {
+		c.key = append(c.key, buf[:binary.PutUvarint(buf[:], uint64(i))]...)
+	}
+	if state, ok := c.states[string(c.key)]; ok {
+		return state
+	}
+
+	state := &dfaState{
+		nfa: append([]int(nil), set...),
+	}
+	for _, i := range set {
+		if c.nfa.states[i].kind == nfaMatch {
+			state.match = true
+		}
+	}
+	c.states[string(c.key)] = state
+
+	return state
+}
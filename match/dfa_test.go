@@ -0,0 +1,150 @@
+package match
+
+import (
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// textNFA adds states matching s followed by next.
+func textNFA(n *NFA, s string, next int) int {
+	chars := []rune(s)
+	for i := len(chars) - 1; i >= 0; i-- {
+		next = n.Rune(NewList(chars[i:i+1], false), next)
+	}
+	return next
+}
+
+// newTestDFA builds DFA for `*a*b` with `.` as separator.
+func newTestDFA() DFA {
+	sep := []rune{'.'}
+	n := NewNFA()
+	start := n.Star(NewSingle(sep), textNFA(n, "a", n.Star(NewSingle(sep), textNFA(n, "b", n.Match()))))
+	// reversed `*a*b` followed by anything, that is anything followed by `b*a*`
+	reverse := n.Star(NewSuper(), textNFA(n, "b", n.Star(NewSingle(sep), textNFA(n, "a", n.Star(NewSingle(sep), n.Match())))))
+	fallback := NewBTree(NewText("a"), NewAny(sep), NewSuffixAny("b", sep))
+	return NewDFA(n, start, reverse, fallback)
+}
+
+func TestDFAMatch(t *testing.T) {
+	d := newTestDFA()
+	for id, test := range []struct {
+		fixture string
+		match   bool
+		prefix  bool
+	}{
+		{"ab", true, true},
+		{"xaxb", true, true},
+		{"aaab", true, true},
+		{"aba", false, true},
+		{"", false, true},
+		{"xa", false, true},
+		{"b", false, true},
+		{"a.b", false, false},
+		{".", false, false},
+		{"äaüb", true, true},
+	} {
+		if act := d.Match(test.fixture); act != test.match {
+			t.Errorf("#%d Match(%q) = %v; want %v", id, test.fixture, act, test.match)
+		}
+		if act := d.CouldMatchPrefix(test.fixture); act != test.prefix {
+			t.Errorf("#%d CouldMatchPrefix(%q) = %v; want %v", id, test.fixture, act, test.prefix)
+		}
+	}
+}
+
+func TestDFAIndex(t *testing.T) {
+	d := newTestDFA()
+	for id, test := range []struct {
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{"x.ab.b", 2, []int{2}},
+		{"xaxb", 0, []int{4}},
+		{"abab.b", 0, []int{2, 4}},
+		{"äaüb", 0, []int{6}},
+		{".a.b", -1, nil},
+		{"ba", -1, nil},
+		{"", -1, nil},
+	} {
+		index, segments := d.Index(test.fixture)
+		if index != test.index || !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d Index(%q) = %d, %v; want %d, %v", id, test.fixture, index, segments, test.index, test.segments)
+		}
+		if fi, fs := d.Fallback.Index(test.fixture); fi != index || !reflect.DeepEqual(fs, segments) {
+			t.Errorf("#%d Index(%q) = %d, %v; fallback reports %d, %v", id, test.fixture, index, segments, fi, fs)
+		}
+	}
+}
+
+func TestDFAFallback(t *testing.T) {
+	d := newTestDFA()
+
+	if captures, ok := d.Capture("xaxb", nil); !ok || strings.Join(captures, ",") != "x,x" {
+		t.Errorf("unexpected capture: %q %v", captures, ok)
+	}
+	if d.Len() != -1 {
+		t.Errorf("unexpected len: %d", d.Len())
+	}
+}
+
+func TestDFACacheFlush(t *testing.T) {
+	// `*a??????????????` needs a state for each combination of
+	// the last 15 characters being `a` or not, that is more than dfaMaxStates
+	n := NewNFA()
+	next := n.Match()
+	for i := 0; i < 15; i++ {
+		next = n.Rune(NewSingle(nil), next)
+	}
+	start := n.Star(NewSingle(nil), textNFA(n, "a", next))
+	reverse := textNFA(n, "a", n.Star(NewSingle(nil), n.Match()))
+	for i := 0; i < 15; i++ {
+		reverse = n.Rune(NewSingle(nil), reverse)
+	}
+	d := NewDFA(n, start, n.Star(NewSuper(), reverse), NewNothing())
+
+	rnd := rand.New(rand.NewSource(1))
+	var b strings.Builder
+	for i := 0; i < 1<<13; i++ {
+		if rnd.Intn(2) == 0 {
+			b.WriteByte('a')
+		} else {
+			b.WriteByte('b')
+		}
+	}
+	s := b.String()
+	for i := 16; i <= len(s); i += 997 {
+		exp := s[i-16] == 'a'
+		if act := d.Match(s[:i]); act != exp {
+			t.Errorf("Match(s[:%d]) = %v; want %v", i, act, exp)
+		}
+	}
+
+	c := d.caches.Get().(*dfaCache)
+	defer d.caches.Put(c)
+	if len(c.states) > dfaMaxStates {
+		t.Errorf("unexpected number of cached states: %d", len(c.states))
+	}
+}
+
+func TestDFAConcurrent(t *testing.T) {
+	d := newTestDFA()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if !d.Match("xaxb") || d.Match("xa.b") {
+					t.Error("unexpected match")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,44 @@
+package match
+
+import "fmt"
+
+// LengthLimit matches strings that are not longer than Limit bytes and are matched by Matcher.
+// Longer strings are rejected without being inspected.
+type LengthLimit struct {
+	Matcher Matcher
+	Limit   int
+}
+
+func NewLengthLimit(m Matcher, limit int) LengthLimit {
+	return LengthLimit{Matcher: m, Limit: limit}
+}
+
+func (l LengthLimit) Match(s string) bool {
+	return len(s) <= l.Limit && l.Matcher.Match(s)
+}
+
+func (l LengthLimit) Index(s string) (int, []int) {
+	if len(s) > l.Limit {
+		return -1, nil
+	}
+	return l.Matcher.Index(s)
+}
+
+func (l LengthLimit) Len() int {
+	return l.Matcher.Len()
+}
+
+func (l LengthLimit) String() string {
+	return fmt.Sprintf("<length_limit:%d:%s>", l.Limit, l.Matcher)
+}
+
+func (l LengthLimit) Capture(s string, dst []string) ([]string, bool) {
+	if len(s) > l.Limit {
+		return dst, false
+	}
+	return Capture(l.Matcher, s, dst)
+}
+
+func (l LengthLimit) CouldMatchPrefix(s string) bool {
+	return len(s) <= l.Limit && l.Matcher.CouldMatchPrefix(s)
+}
@@ -0,0 +1,36 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLengthLimit(t *testing.T) {
+	m := NewLengthLimit(NewPrefixAny("ab", nil), 4)
+	for id, test := range []struct {
+		fixture  string
+		match    bool
+		prefix   bool
+		index    int
+		segments []int
+	}{
+		{"ab", true, true, 0, []int{2}},
+		{"abcd", true, true, 0, []int{2, 3, 4}},
+		{"xab", false, false, 1, []int{2}},
+		{"abcde", false, false, -1, nil},
+	} {
+		if act := m.Match(test.fixture); act != test.match {
+			t.Errorf("#%d unexpected match of %q: exp: %v, act: %v", id, test.fixture, test.match, act)
+		}
+		if act := m.CouldMatchPrefix(test.fixture); act != test.prefix {
+			t.Errorf("#%d unexpected prefix match of %q: exp: %v, act: %v", id, test.fixture, test.prefix, act)
+		}
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
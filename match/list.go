@@ -0,0 +1,87 @@
+package match
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/util/runes"
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+// List represents single character from the list (or not from the list if Not is set).
+// If Fold is set, characters are compared under Unicode simple case folding.
+type List struct {
+	List []rune
+	Not  bool
+	Fold bool
+}
+
+func NewList(list []rune, not bool) List {
+	return List{List: list, Not: not}
+}
+
+func (l List) contains(r rune) bool {
+	if !l.Fold {
+		return runes.IndexRune(l.List, r) != -1
+	}
+	for _, c := range l.List {
+		if sutil.EqualFoldRune(c, r) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l List) Match(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	r, w := utf8.DecodeRuneInString(s)
+	if len(s) > w {
+		return false
+	}
+
+	return l.MatchRune(r)
+}
+
+func (l List) MatchRune(r rune) bool {
+	return l.contains(r) == !l.Not
+}
+
+func (l List) Len() int {
+	return lenOne
+}
+
+func (l List) Index(s string) (int, []int) {
+	for i := 0; i < len(s); {
+		// width of invalid encoding is 1, while RuneLen(RuneError) is 3
+		r, w := utf8.DecodeRuneInString(s[i:])
+		if l.Not != l.contains(r) {
+			return i, segmentsByRuneLength[w]
+		}
+		i += w
+	}
+
+	return -1, nil
+}
+
+func (l List) String() string {
+	var not string
+	if l.Not {
+		not = "!"
+	}
+
+	return fmt.Sprintf("<list:%s[%s]%s>", not, string(l.List), foldFlag(l.Fold))
+}
+
+func (l List) Capture(s string, dst []string) ([]string, bool) {
+	if !l.Match(s) {
+		return dst, false
+	}
+	return append(dst, s), true
+}
+
+func (l List) CouldMatchPrefix(s string) bool {
+	return len(s) == 0 || l.Match(s)
+}
@@ -8,6 +8,7 @@ import (
 )
 
 const lenZero = 0
+const lenOne = 1
 const lenNo = -1
 
 type Matcher interface {
@@ -15,10 +16,39 @@ type Matcher interface {
 	Index(string) (int, []int)
 	Len() int
 	String() string
+
+	// CouldMatchPrefix reports whether some string beginning with the given
+	// prefix could match. It may return true for a prefix no string beginning
+	// with matches, but never returns false for one that does.
+	CouldMatchPrefix(string) bool
+}
+
+// Capturer is implemented by matchers that can report text consumed by their wildcards.
+// Each matcher reports the same number of captures for every matching string.
+type Capturer interface {
+	// Capture appends to dst texts consumed by each wildcard if s matches.
+	Capture(s string, dst []string) ([]string, bool)
+}
+
+// Capture appends to dst texts consumed by wildcards of m if s matches m.
+// Matchers that do not implement Capturer report no captures.
+func Capture(m Matcher, s string, dst []string) ([]string, bool) {
+	if c, ok := m.(Capturer); ok {
+		return c.Capture(s, dst)
+	}
+	return dst, m.Match(s)
 }
 
 type Matchers []Matcher
 
+// foldFlag returns suffix for String() of matchers compiled with case folding.
+func foldFlag(fold bool) string {
+	if fold {
+		return "/i"
+	}
+	return ""
+}
+
 func (m Matchers) String() string {
 	var s []string
 	for _, matcher := range m {
@@ -31,7 +61,7 @@ func (m Matchers) String() string {
 // appendMerge merges and sorts given already SORTED and UNIQUE segments.
 func appendMerge(target, sub []int) []int {
 	lt, ls := len(target), len(sub)
-	out := make([]int, 0, lt+ls)
+	out := acquireSegments(lt + ls)
 
 	for x, y := 0, 0; x < lt || y < ls; {
 		if x >= lt {
@@ -66,6 +96,7 @@ func appendMerge(target, sub []int) []int {
 	}
 
 	target = append(target[:0], out...)
+	releaseSegments(out)
 
 	return target
 }
@@ -21,3 +21,11 @@ func (n Nothing) Len() int {
 func (n Nothing) String() string {
 	return "<nothing>"
 }
+
+func (n Nothing) Capture(s string, dst []string) ([]string, bool) {
+	return dst, n.Match(s)
+}
+
+func (n Nothing) CouldMatchPrefix(s string) bool {
+	return len(s) == 0
+}
@@ -8,22 +8,24 @@ import (
 	sutil "github.com/gopherlib/simple-glob/util/strings"
 )
 
+// PrefixAny represents text followed by any sequence of non-separator characters.
+// If Fold is set, the prefix is compared under Unicode simple case folding.
 type PrefixAny struct {
 	Prefix     string
 	Separators []rune
+	Fold       bool
 }
 
 func NewPrefixAny(s string, sep []rune) PrefixAny {
-	return PrefixAny{s, sep}
+	return PrefixAny{Prefix: s, Separators: sep}
 }
 
 func (a PrefixAny) Index(s string) (int, []int) {
-	idx := strings.Index(s, a.Prefix)
+	idx, n := a.indexPrefix(s)
 	if idx == -1 {
 		return -1, nil
 	}
 
-	n := len(a.Prefix)
 	sub := s[idx+n:]
 	i := sutil.IndexAnyRunes(sub, a.Separators)
 	if i > -1 {
@@ -32,8 +34,10 @@ func (a PrefixAny) Index(s string) (int, []int) {
 
 	seg := acquireSegments(len(sub) + 1)
 	seg = append(seg, n)
-	for i, r := range sub {
-		seg = append(seg, n+i+utf8.RuneLen(r))
+	for i := 0; i < len(sub); {
+		_, w := utf8.DecodeRuneInString(sub[i:])
+		i += w
+		seg = append(seg, n+i)
 	}
 
 	return idx, seg
@@ -44,12 +48,45 @@ func (a PrefixAny) Len() int {
 }
 
 func (a PrefixAny) Match(s string) bool {
-	if !strings.HasPrefix(s, a.Prefix) {
+	n, ok := a.hasPrefix(s)
+	if !ok {
 		return false
 	}
-	return sutil.IndexAnyRunes(s[len(a.Prefix):], a.Separators) == -1
+	return sutil.IndexAnyRunes(s[n:], a.Separators) == -1
+}
+
+// hasPrefix reports whether s begins with the prefix and returns its length in s.
+func (a PrefixAny) hasPrefix(s string) (int, bool) {
+	if a.Fold {
+		return sutil.HasPrefixFold(s, a.Prefix)
+	}
+	return len(a.Prefix), strings.HasPrefix(s, a.Prefix)
+}
+
+// indexPrefix returns the index of the first prefix instance in s and its length.
+func (a PrefixAny) indexPrefix(s string) (int, int) {
+	if a.Fold {
+		return sutil.IndexFold(s, a.Prefix)
+	}
+	return strings.Index(s, a.Prefix), len(a.Prefix)
 }
 
 func (a PrefixAny) String() string {
-	return fmt.Sprintf("<prefix_any:%s![%s]>", a.Prefix, string(a.Separators))
+	return fmt.Sprintf("<prefix_any:%s%s![%s]>", a.Prefix, foldFlag(a.Fold), string(a.Separators))
+}
+
+func (a PrefixAny) Capture(s string, dst []string) ([]string, bool) {
+	if !a.Match(s) {
+		return dst, false
+	}
+	n, _ := a.hasPrefix(s)
+	return append(dst, s[n:]), true
+}
+
+func (a PrefixAny) CouldMatchPrefix(s string) bool {
+	if n, ok := a.hasPrefix(s); ok {
+		return sutil.IndexAnyRunes(s[n:], a.Separators) == -1
+	}
+	// s ends inside of the prefix
+	return NewText(a.Prefix).withFold(a.Fold).CouldMatchPrefix(s)
 }
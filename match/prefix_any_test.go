@@ -34,6 +34,13 @@ func TestPrefixAnyIndex(t *testing.T) {
 			3,
 			[]int{2, 3, 4},
 		},
+		{
+			"ab",
+			nil,
+			"ab\xff",
+			0,
+			[]int{2, 3},
+		},
 	} {
 		p := NewPrefixAny(test.prefix, test.separators)
 		index, segments := p.Index(test.fixture)
@@ -45,3 +52,52 @@ func TestPrefixAnyIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestPrefixAnyCapture(t *testing.T) {
+	for id, test := range []struct {
+		prefix     string
+		separators []rune
+		fixture    string
+		captures   []string
+		ok         bool
+	}{
+		{"ab", []rune{'.'}, "abcd", []string{"cd"}, true},
+		{"ab", []rune{'.'}, "ab", []string{""}, true},
+		{"ab", []rune{'.'}, "ab.cd", nil, false},
+	} {
+		captures, ok := NewPrefixAny(test.prefix, test.separators).Capture(test.fixture, nil)
+		if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func TestPrefixAnyFold(t *testing.T) {
+	for id, test := range []struct {
+		prefix   string
+		fixture  string
+		index    int
+		segments []int
+		captures []string
+	}{
+		{"ab", "ABcd", 0, []int{2, 3, 4}, []string{"cd"}},
+		{"s", "ſx", 0, []int{2, 3}, []string{"x"}},
+		{"b", "aBc.d", 1, []int{1, 2}, nil},
+	} {
+		m := NewPrefixAny(test.prefix, []rune{'.'})
+		m.Fold = true
+
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+
+		captures, _ := m.Capture(test.fixture, nil)
+		if !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q, act: %q", id, test.captures, captures)
+		}
+	}
+}
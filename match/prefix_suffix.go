@@ -3,39 +3,45 @@ package match
 import (
 	"fmt"
 	"strings"
+
+	sutil "github.com/gopherlib/simple-glob/util/strings"
 )
 
+// PrefixSuffix represents any string that begins with Prefix and ends with Suffix,
+// which do not overlap. If Fold is set, both are compared under Unicode simple case folding.
 type PrefixSuffix struct {
 	Prefix, Suffix string
+	Fold           bool
 }
 
 func NewPrefixSuffix(p, s string) PrefixSuffix {
-	return PrefixSuffix{p, s}
+	return PrefixSuffix{Prefix: p, Suffix: s}
 }
 
 func (p PrefixSuffix) Index(s string) (int, []int) {
-	prefixIdx := strings.Index(s, p.Prefix)
+	var prefixIdx, prefixLen int
+	if p.Fold {
+		prefixIdx, prefixLen = sutil.IndexFold(s, p.Prefix)
+	} else {
+		prefixIdx, prefixLen = strings.Index(s, p.Prefix), len(p.Prefix)
+	}
 	if prefixIdx == -1 {
 		return -1, nil
 	}
 
-	suffixLen := len(p.Suffix)
-	if suffixLen <= 0 {
+	if len(p.Suffix) <= 0 {
 		return prefixIdx, []int{len(s) - prefixIdx}
 	}
 
-	if (len(s) - prefixIdx) <= 0 {
-		return -1, nil
-	}
-
+	// suffix must begin after the end of the prefix
 	segments := acquireSegments(len(s) - prefixIdx)
-	for sub := s[prefixIdx:]; ; {
-		suffixIdx := strings.LastIndex(sub, p.Suffix)
+	for sub := s[prefixIdx+prefixLen:]; ; {
+		suffixIdx, suffixLen := p.lastIndexSuffix(sub)
 		if suffixIdx == -1 {
 			break
 		}
 
-		segments = append(segments, suffixIdx+suffixLen)
+		segments = append(segments, prefixLen+suffixIdx+suffixLen)
 		sub = sub[:suffixIdx]
 	}
 
@@ -54,9 +60,54 @@ func (p PrefixSuffix) Len() int {
 }
 
 func (p PrefixSuffix) Match(s string) bool {
-	return strings.HasPrefix(s, p.Prefix) && strings.HasSuffix(s, p.Suffix)
+	pn, sn, ok := p.affixes(s)
+	return ok && len(s) >= pn+sn
+}
+
+// affixes returns lengths of the prefix and the suffix in s.
+func (p PrefixSuffix) affixes(s string) (int, int, bool) {
+	if !p.Fold {
+		ok := strings.HasPrefix(s, p.Prefix) && strings.HasSuffix(s, p.Suffix)
+		return len(p.Prefix), len(p.Suffix), ok
+	}
+
+	pn, ok := sutil.HasPrefixFold(s, p.Prefix)
+	if !ok {
+		return 0, 0, false
+	}
+	sn, ok := sutil.HasSuffixFold(s, p.Suffix)
+	return pn, sn, ok
+}
+
+// lastIndexSuffix returns the index of the last suffix instance in s and its length.
+func (p PrefixSuffix) lastIndexSuffix(s string) (int, int) {
+	if p.Fold {
+		return sutil.LastIndexFold(s, p.Suffix)
+	}
+	return strings.LastIndex(s, p.Suffix), len(p.Suffix)
 }
 
 func (p PrefixSuffix) String() string {
-	return fmt.Sprintf("<prefix_suffix:[%s,%s]>", p.Prefix, p.Suffix)
+	return fmt.Sprintf("<prefix_suffix:[%s,%s]%s>", p.Prefix, p.Suffix, foldFlag(p.Fold))
+}
+
+func (p PrefixSuffix) Capture(s string, dst []string) ([]string, bool) {
+	pn, sn, ok := p.affixes(s)
+	if !ok || len(s) < pn+sn {
+		return dst, false
+	}
+	return append(dst, s[pn:len(s)-sn]), true
+}
+
+func (p PrefixSuffix) CouldMatchPrefix(s string) bool {
+	prefix := NewText(p.Prefix).withFold(p.Fold)
+	if prefix.CouldMatchPrefix(s) {
+		return true
+	}
+	// s begins with the whole prefix, so any suffix could follow
+	if p.Fold {
+		_, ok := sutil.HasPrefixFold(s, p.Prefix)
+		return ok
+	}
+	return strings.HasPrefix(s, p.Prefix)
 }
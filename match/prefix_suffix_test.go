@@ -25,14 +25,21 @@ func TestPrefixSuffixIndex(t *testing.T) {
 			"f",
 			"fffabfff",
 			0,
-			[]int{1, 2, 3, 6, 7, 8},
+			[]int{2, 3, 6, 7, 8},
 		},
 		{
 			"ab",
 			"bc",
 			"abc",
-			0,
-			[]int{3},
+			-1,
+			nil,
+		},
+		{
+			"ab",
+			"bc",
+			"xabbc",
+			1,
+			[]int{4},
 		},
 	} {
 		p := NewPrefixSuffix(test.prefix, test.suffix)
@@ -65,3 +72,67 @@ func BenchmarkIndexPrefixSuffixParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestPrefixSuffixMatch(t *testing.T) {
+	for id, test := range []struct {
+		prefix, suffix string
+		fixture        string
+		should         bool
+	}{
+		{"ab", "ba", "abba", true},
+		{"ab", "ba", "aba", false},
+		{"a", "a", "a", false},
+		{"a", "a", "aa", true},
+	} {
+		if act := NewPrefixSuffix(test.prefix, test.suffix).Match(test.fixture); act != test.should {
+			t.Errorf("#%d unexpected match of %q: exp: %v, act: %v", id, test.fixture, test.should, act)
+		}
+	}
+}
+
+func TestPrefixSuffixCapture(t *testing.T) {
+	for id, test := range []struct {
+		prefix, suffix string
+		fixture        string
+		captures       []string
+		ok             bool
+	}{
+		{"https://", ".com", "https://example.com", []string{"example"}, true},
+		{"ab", "ba", "aba", nil, false},
+		{"a", "c", "ab", nil, false},
+	} {
+		captures, ok := NewPrefixSuffix(test.prefix, test.suffix).Capture(test.fixture, nil)
+		if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func TestPrefixSuffixFold(t *testing.T) {
+	for id, test := range []struct {
+		prefix, suffix string
+		fixture        string
+		index          int
+		segments       []int
+		captures       []string
+	}{
+		{"a", "c", "xAbCc", 1, []int{3, 4}, []string{"bC"}},
+		{"ſ", "S", "sxſ", 0, []int{4}, []string{"x"}},
+	} {
+		m := NewPrefixSuffix(test.prefix, test.suffix)
+		m.Fold = true
+
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+
+		captures, _ := m.Capture(test.fixture[index:], nil)
+		if !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q, act: %q", id, test.captures, captures)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+package match
+
+import (
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/util/runes"
+)
+
+// Range represents single character within [Lo, Hi] (or outside of it if Not is set).
+// Separators are never matched by negated range.
+// If Fold is set, character matches if any of its case variants is within the range.
+type Range struct {
+	Lo, Hi     rune
+	Not        bool
+	Separators []rune
+	Fold       bool
+}
+
+func NewRange(lo, hi rune, not bool, sep []rune) Range {
+	return Range{Lo: lo, Hi: hi, Not: not, Separators: sep}
+}
+
+func (r Range) contains(c rune) bool {
+	if c >= r.Lo && c <= r.Hi {
+		return true
+	}
+	if !r.Fold {
+		return false
+	}
+	for f := unicode.SimpleFold(c); f != c; f = unicode.SimpleFold(f) {
+		if f >= r.Lo && f <= r.Hi {
+			return true
+		}
+	}
+	return false
+}
+
+func (r Range) Match(s string) bool {
+	if len(s) == 0 {
+		return false
+	}
+
+	c, w := utf8.DecodeRuneInString(s)
+	if len(s) > w {
+		return false
+	}
+
+	return r.MatchRune(c)
+}
+
+func (r Range) MatchRune(c rune) bool {
+	inRange := r.contains(c)
+	if !r.Not {
+		return inRange
+	}
+
+	return !inRange && runes.IndexRune(r.Separators, c) == -1
+}
+
+func (r Range) Len() int {
+	return lenOne
+}
+
+func (r Range) Index(s string) (int, []int) {
+	for i := 0; i < len(s); {
+		// width of invalid encoding is 1, while RuneLen(RuneError) is 3
+		c, w := utf8.DecodeRuneInString(s[i:])
+		if r.MatchRune(c) {
+			return i, segmentsByRuneLength[w]
+		}
+		i += w
+	}
+
+	return -1, nil
+}
+
+func (r Range) String() string {
+	var not string
+	if r.Not {
+		not = "!"
+	}
+
+	return fmt.Sprintf("<range:%s[%s,%s]%s>", not, string(r.Lo), string(r.Hi), foldFlag(r.Fold))
+}
+
+func (r Range) Capture(s string, dst []string) ([]string, bool) {
+	if !r.Match(s) {
+		return dst, false
+	}
+	return append(dst, s), true
+}
+
+func (r Range) CouldMatchPrefix(s string) bool {
+	return len(s) == 0 || r.Match(s)
+}
@@ -0,0 +1,76 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRangeIndex(t *testing.T) {
+	for id, test := range []struct {
+		lo, hi     rune
+		not        bool
+		separators []rune
+		fixture    string
+		index      int
+		segments   []int
+	}{
+		{
+			'a', 'z',
+			false,
+			nil,
+			"0123a",
+			4,
+			[]int{1},
+		},
+		{
+			'a', 'z',
+			true,
+			nil,
+			"abc.",
+			3,
+			[]int{1},
+		},
+		{
+			'a', 'z',
+			true,
+			[]rune{'.'},
+			"abc.",
+			-1,
+			nil,
+		},
+		{
+			'日', '語',
+			false,
+			nil,
+			"ab本",
+			2,
+			[]int{3},
+		},
+		{
+			'a', 'z',
+			true,
+			nil,
+			"a\xffb",
+			1,
+			[]int{1},
+		},
+	} {
+		p := NewRange(test.lo, test.hi, test.not, test.separators)
+		index, segments := p.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
+
+func BenchmarkIndexRange(b *testing.B) {
+	m := NewRange('f', 'h', false, nil)
+
+	for i := 0; i < b.N; i++ {
+		_, s := m.Index(bench_pattern)
+		releaseSegments(s)
+	}
+}
@@ -2,6 +2,7 @@ package match
 
 import (
 	"fmt"
+	"unicode/utf8"
 )
 
 type Row struct {
@@ -18,27 +19,31 @@ func NewRow(len int, m ...Matcher) Row {
 	}
 }
 
-func (r Row) matchAll(s string) bool {
+// matchAll matches prefix of s against all matchers in order.
+// It returns byte length of the matched prefix or -1 if it does not match.
+func (r Row) matchAll(s string) int {
 	var idx int
 	for _, m := range r.Matchers {
 		length := m.Len()
 
-		var next, i int
-		for next = range s[idx:] {
-			i++
-			if i == length {
-				break
+		// find the byte offset where the next length runes end
+		next := idx
+		for i := 0; i < length; i++ {
+			if next >= len(s) {
+				return -1
 			}
+			_, w := utf8.DecodeRuneInString(s[next:])
+			next += w
 		}
 
-		if i < length || !m.Match(s[idx:idx+next+1]) {
-			return false
+		if !m.Match(s[idx:next]) {
+			return -1
 		}
 
-		idx += next + 1
+		idx = next
 	}
 
-	return true
+	return idx
 }
 
 func (r Row) lenOk(s string) bool {
@@ -53,7 +58,7 @@ func (r Row) lenOk(s string) bool {
 }
 
 func (r Row) Match(s string) bool {
-	return r.lenOk(s) && r.matchAll(s)
+	return r.lenOk(s) && r.matchAll(s) != -1
 }
 
 func (r Row) Len() (l int) {
@@ -65,8 +70,12 @@ func (r Row) Index(s string) (int, []int) {
 		if len(s[i:]) < r.RunesLength {
 			break
 		}
-		if r.matchAll(s[i:]) {
-			return i, r.Segments
+		if n := r.matchAll(s[i:]); n != -1 {
+			if utf8.RuneCountInString(s[i:i+n]) == n {
+				return i, r.Segments
+			}
+			// matched part contains multibyte runes
+			return i, []int{n}
 		}
 	}
 	return -1, nil
@@ -75,3 +84,51 @@ func (r Row) Index(s string) (int, []int) {
 func (r Row) String() string {
 	return fmt.Sprintf("<row_%d:[%s]>", r.RunesLength, r.Matchers)
 }
+
+func (r Row) Capture(s string, dst []string) ([]string, bool) {
+	if !r.lenOk(s) {
+		return dst, false
+	}
+
+	n := len(dst)
+
+	var idx int
+	for _, m := range r.Matchers {
+		next := idx
+		for i := 0; i < m.Len(); i++ {
+			_, w := utf8.DecodeRuneInString(s[next:])
+			next += w
+		}
+
+		var ok bool
+		if dst, ok = Capture(m, s[idx:next], dst); !ok {
+			return dst[:n], false
+		}
+		idx = next
+	}
+
+	return dst, true
+}
+
+func (r Row) CouldMatchPrefix(s string) bool {
+	var idx int
+	for _, m := range r.Matchers {
+		// find the byte offset where the next length runes end
+		next := idx
+		for i := 0; i < m.Len() && next < len(s); i++ {
+			_, w := utf8.DecodeRuneInString(s[next:])
+			next += w
+		}
+
+		if next == len(s) {
+			// s ends inside of this matcher or right after it
+			return m.CouldMatchPrefix(s[idx:])
+		}
+		if !m.Match(s[idx:next]) {
+			return false
+		}
+		idx = next
+	}
+
+	return idx == len(s)
+}
@@ -33,6 +33,16 @@ func TestRowIndex(t *testing.T) {
 			-1,
 			nil,
 		},
+		{
+			Matchers{
+				NewSingle(nil),
+				NewText("bc"),
+			},
+			3,
+			"a日bcd",
+			1,
+			[]int{5},
+		},
 	} {
 		p := NewRow(test.length, test.matchers...)
 		index, segments := p.Index(test.fixture)
@@ -76,3 +86,40 @@ func BenchmarkIndexRowParallel(b *testing.B) {
 		}
 	})
 }
+
+func TestRowCapture(t *testing.T) {
+	r := NewRow(4, NewText("a"), NewSingle(nil), NewText("c"), NewList([]rune("xy"), false))
+
+	captures, ok := r.Capture("a日cx", []string{"head"})
+	if exp := []string{"head", "日", "x"}; !ok || !reflect.DeepEqual(captures, exp) {
+		t.Errorf("unexpected capture: exp: %q, act: %q %v", exp, captures, ok)
+	}
+
+	captures, ok = r.Capture("abcz", []string{"head"})
+	if exp := []string{"head"}; ok || !reflect.DeepEqual(captures, exp) {
+		t.Errorf("unexpected capture: exp: %q, act: %q %v", exp, captures, ok)
+	}
+}
+
+func TestRowCouldMatchPrefix(t *testing.T) {
+	r := NewRow(5, NewText("ab"), NewSingle([]rune{'.'}), NewText("c"), NewList([]rune("xy"), false))
+	for id, test := range []struct {
+		prefix string
+		should bool
+	}{
+		{"", true},
+		{"a", true},
+		{"ab", true},
+		{"ab日", true},
+		{"ab.", false},
+		{"ab日c", true},
+		{"ab日cx", true},
+		{"ab日cz", false},
+		{"ab日cxx", false},
+		{"b", false},
+	} {
+		if act := r.CouldMatchPrefix(test.prefix); act != test.should {
+			t.Errorf("#%d CouldMatchPrefix(%q) = %v; want %v", id, test.prefix, act, test.should)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package match
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/util/runes"
+)
+
+// Single represents exactly one non-separator character.
+type Single struct {
+	Separators []rune
+}
+
+func NewSingle(s []rune) Single {
+	return Single{s}
+}
+
+func (s Single) Match(str string) bool {
+	if len(str) == 0 {
+		return false
+	}
+
+	r, w := utf8.DecodeRuneInString(str)
+	if len(str) > w {
+		return false
+	}
+
+	return s.MatchRune(r)
+}
+
+func (s Single) MatchRune(r rune) bool {
+	return runes.IndexRune(s.Separators, r) == -1
+}
+
+func (s Single) Len() int {
+	return lenOne
+}
+
+func (s Single) Index(str string) (int, []int) {
+	for i := 0; i < len(str); {
+		// width of invalid encoding is 1, while RuneLen(RuneError) is 3
+		r, w := utf8.DecodeRuneInString(str[i:])
+		if runes.IndexRune(s.Separators, r) == -1 {
+			return i, segmentsByRuneLength[w]
+		}
+		i += w
+	}
+
+	return -1, nil
+}
+
+func (s Single) String() string {
+	return fmt.Sprintf("<single:![%s]>", string(s.Separators))
+}
+
+func (s Single) Capture(str string, dst []string) ([]string, bool) {
+	if !s.Match(str) {
+		return dst, false
+	}
+	return append(dst, str), true
+}
+
+func (s Single) CouldMatchPrefix(str string) bool {
+	return len(str) == 0 || s.Match(str)
+}
@@ -0,0 +1,77 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSingleIndex(t *testing.T) {
+	for id, test := range []struct {
+		separators []rune
+		fixture    string
+		index      int
+		segments   []int
+	}{
+		{
+			[]rune{'.'},
+			".abc",
+			1,
+			[]int{1},
+		},
+		{
+			[]rune{'.'},
+			".",
+			-1,
+			nil,
+		},
+		{
+			nil,
+			"日本",
+			0,
+			[]int{3},
+		},
+		{
+			[]rune{'.'},
+			".\xffb",
+			1,
+			[]int{1},
+		},
+	} {
+		p := NewSingle(test.separators)
+		index, segments := p.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+	}
+}
+
+func TestSingleMatch(t *testing.T) {
+	for id, test := range []struct {
+		separators []rune
+		fixture    string
+		exp        bool
+	}{
+		{nil, "a", true},
+		{nil, "日", true},
+		{nil, "", false},
+		{nil, "ab", false},
+		{[]rune{'.'}, ".", false},
+	} {
+		act := NewSingle(test.separators).Match(test.fixture)
+		if act != test.exp {
+			t.Errorf("#%d match %q error: act: %t; exp: %t", id, test.fixture, act, test.exp)
+		}
+	}
+}
+
+func BenchmarkIndexSingle(b *testing.B) {
+	m := NewSingle(bench_separators)
+
+	for i := 0; i < b.N; i++ {
+		_, s := m.Index(bench_pattern)
+		releaseSegments(s)
+	}
+}
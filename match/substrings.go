@@ -0,0 +1,91 @@
+package match
+
+import (
+	"fmt"
+	"strings"
+
+	sutil "github.com/gopherlib/simple-glob/util/strings"
+)
+
+// Substrings represents any string that contains Strs in order without overlapping,
+// that is Strs separated and surrounded by any sequences of characters.
+// If Fold is set, the strings are compared under Unicode simple case folding.
+type Substrings struct {
+	Strs []string
+	Fold bool
+}
+
+func NewSubstrings(strs ...string) Substrings {
+	return Substrings{Strs: strs}
+}
+
+func (m Substrings) Match(s string) bool {
+	return m.end(s) != -1
+}
+
+// Index returns 0 and lengths of all prefixes of s that contain the strings.
+func (m Substrings) Index(s string) (int, []int) {
+	end := m.end(s)
+	if end == -1 {
+		return -1, nil
+	}
+	return 0, appendRuneEnds(acquireSegments(len(s)-end+1), s, end)
+}
+
+// end returns the end of the shortest prefix of s containing the strings, or -1.
+// The leftmost instance of each string is the best choice for the following ones.
+func (m Substrings) end(s string) int {
+	var end int
+	for _, str := range m.Strs {
+		var idx, n int
+		if m.Fold {
+			idx, n = sutil.IndexFold(s[end:], str)
+		} else {
+			idx, n = strings.Index(s[end:], str), len(str)
+		}
+		if idx == -1 {
+			return -1
+		}
+		end += idx + n
+	}
+	return end
+}
+
+func (m Substrings) Len() int {
+	return lenNo
+}
+
+func (m Substrings) String() string {
+	return fmt.Sprintf("<substrings:[%s]%s>", strings.Join(m.Strs, ","), foldFlag(m.Fold))
+}
+
+// Capture appends texts around the strings. Strings are searched from the end,
+// so texts before them are as long as possible.
+func (m Substrings) Capture(s string, dst []string) ([]string, bool) {
+	base := len(dst)
+	for i := 0; i <= len(m.Strs); i++ {
+		dst = append(dst, "")
+	}
+
+	end := len(s)
+	for i := len(m.Strs) - 1; i >= 0; i-- {
+		var idx, n int
+		if m.Fold {
+			idx, n = sutil.LastIndexFold(s[:end], m.Strs[i])
+		} else {
+			idx, n = strings.LastIndex(s[:end], m.Strs[i]), len(m.Strs[i])
+		}
+		if idx == -1 {
+			return dst[:base], false
+		}
+		dst[base+i+1] = s[idx+n : end]
+		end = idx
+	}
+	dst[base] = s[:end]
+
+	return dst, true
+}
+
+func (m Substrings) CouldMatchPrefix(string) bool {
+	return true
+}
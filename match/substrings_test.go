@@ -0,0 +1,62 @@
+package match
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSubstringsIndex(t *testing.T) {
+	for id, test := range []struct {
+		strs     []string
+		fold     bool
+		fixture  string
+		index    int
+		segments []int
+	}{
+		{[]string{"a", "b"}, false, "ab", 0, []int{2}},
+		{[]string{"a", "b"}, false, "babxb", 0, []int{3, 4, 5}},
+		{[]string{"a", "b"}, false, "ba", -1, nil},
+		{[]string{"aa", "a"}, false, "aa", -1, nil},
+		{[]string{"A", "B"}, true, "xaxb", 0, []int{4}},
+	} {
+		m := NewSubstrings(test.strs...)
+		m.Fold = test.fold
+
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+		if act := m.Match(test.fixture); act != (test.index != -1) {
+			t.Errorf("#%d unexpected match: %v", id, act)
+		}
+	}
+}
+
+func TestSubstringsCapture(t *testing.T) {
+	for id, test := range []struct {
+		strs     []string
+		fixture  string
+		captures []string
+		ok       bool
+	}{
+		{[]string{"a", "b"}, "xaybazb", []string{"xayb", "z", ""}, true},
+		{[]string{"a", "a"}, "aa", []string{"", "", ""}, true},
+		{[]string{"a", "b"}, "ba", nil, false},
+	} {
+		captures, ok := NewSubstrings(test.strs...).Capture(test.fixture, []string{})
+		if ok != test.ok || (ok && !reflect.DeepEqual(captures, test.captures)) || (!ok && len(captures) != 0) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func BenchmarkMatchSubstrings(b *testing.B) {
+	m := NewSubstrings("qew", "sqw")
+
+	for i := 0; i < b.N; i++ {
+		m.Match(bench_pattern)
+	}
+}
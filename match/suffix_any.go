@@ -3,28 +3,55 @@ package match
 import (
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	sutil "github.com/gopherlib/simple-glob/util/strings"
 )
 
+// SuffixAny represents any sequence of non-separator characters followed by text.
+// If Fold is set, the suffix is compared under Unicode simple case folding.
 type SuffixAny struct {
 	Suffix     string
 	Separators []rune
+	Fold       bool
 }
 
 func NewSuffixAny(s string, sep []rune) SuffixAny {
-	return SuffixAny{s, sep}
+	return SuffixAny{Suffix: s, Separators: sep}
 }
 
 func (a SuffixAny) Index(s string) (int, []int) {
-	idx := strings.Index(s, a.Suffix)
+	idx, n := a.indexSuffix(s)
 	if idx == -1 {
 		return -1, nil
 	}
 
 	i := sutil.LastIndexAnyRunes(s[:idx], a.Separators) + 1
 
-	return i, []int{idx + len(a.Suffix) - i}
+	// any further suffix occurrence that is not preceded by a separator
+	// gives one more match starting at i
+	limit := len(s)
+	if j := sutil.IndexAnyRunes(s[i:], a.Separators); j != -1 {
+		limit = i + j
+	}
+
+	segments := acquireSegments(1)
+	for idx <= limit {
+		segments = append(segments, idx+n-i)
+
+		_, w := utf8.DecodeRuneInString(s[idx:])
+		if w == 0 {
+			break
+		}
+		next, m := a.indexSuffix(s[idx+w:])
+		if next == -1 {
+			break
+		}
+		idx += next + w
+		n = m
+	}
+
+	return i, segments
 }
 
 func (a SuffixAny) Len() int {
@@ -32,12 +59,54 @@ func (a SuffixAny) Len() int {
 }
 
 func (a SuffixAny) Match(s string) bool {
-	if !strings.HasSuffix(s, a.Suffix) {
+	n, ok := a.hasSuffix(s)
+	if !ok {
 		return false
 	}
-	return sutil.IndexAnyRunes(s[:len(s)-len(a.Suffix)], a.Separators) == -1
+	return sutil.IndexAnyRunes(s[:len(s)-n], a.Separators) == -1
+}
+
+// hasSuffix reports whether s ends with the suffix and returns its length in s.
+func (a SuffixAny) hasSuffix(s string) (int, bool) {
+	if a.Fold {
+		return sutil.HasSuffixFold(s, a.Suffix)
+	}
+	return len(a.Suffix), strings.HasSuffix(s, a.Suffix)
+}
+
+// indexSuffix returns the index of the first suffix instance in s and its length.
+func (a SuffixAny) indexSuffix(s string) (int, int) {
+	if a.Fold {
+		return sutil.IndexFold(s, a.Suffix)
+	}
+	return strings.Index(s, a.Suffix), len(a.Suffix)
 }
 
 func (a SuffixAny) String() string {
-	return fmt.Sprintf("<suffix_any:![%s]%s>", string(a.Separators), a.Suffix)
+	return fmt.Sprintf("<suffix_any:![%s]%s%s>", string(a.Separators), a.Suffix, foldFlag(a.Fold))
+}
+
+func (a SuffixAny) Capture(s string, dst []string) ([]string, bool) {
+	if !a.Match(s) {
+		return dst, false
+	}
+	n, _ := a.hasSuffix(s)
+	return append(dst, s[:len(s)-n]), true
+}
+
+func (a SuffixAny) CouldMatchPrefix(s string) bool {
+	first := sutil.IndexAnyRunes(s, a.Separators)
+	if first == -1 {
+		return true
+	}
+
+	// separator could only be a part of the suffix,
+	// so the suffix must begin before it and s must end inside of the suffix
+	suffix := NewText(a.Suffix).withFold(a.Fold)
+	for i := range s[:first+1] {
+		if suffix.CouldMatchPrefix(s[i:]) {
+			return true
+		}
+	}
+	return false
 }
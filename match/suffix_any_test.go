@@ -34,6 +34,20 @@ func TestSuffixAnyIndex(t *testing.T) {
 			3,
 			[]int{4},
 		},
+		{
+			"ab",
+			[]rune{'.'},
+			"qw.abcab.ab",
+			3,
+			[]int{2, 5},
+		},
+		{
+			".com",
+			[]rune{'.'},
+			"a.b.com.com",
+			2,
+			[]int{5},
+		},
 	} {
 		p := NewSuffixAny(test.suffix, test.separators)
 		index, segments := p.Index(test.fixture)
@@ -45,3 +59,72 @@ func TestSuffixAnyIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestSuffixAnyCapture(t *testing.T) {
+	for id, test := range []struct {
+		suffix     string
+		separators []rune
+		fixture    string
+		captures   []string
+		ok         bool
+	}{
+		{".com", []rune{'/'}, "example.com", []string{"example"}, true},
+		{".com", []rune{'/'}, "a/example.com", nil, false},
+	} {
+		captures, ok := NewSuffixAny(test.suffix, test.separators).Capture(test.fixture, nil)
+		if ok != test.ok || !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q %v, act: %q %v", id, test.captures, test.ok, captures, ok)
+		}
+	}
+}
+
+func TestSuffixAnyFold(t *testing.T) {
+	for id, test := range []struct {
+		suffix   string
+		fixture  string
+		index    int
+		segments []int
+		captures []string
+	}{
+		{".com", "example.COM", 0, []int{11}, []string{"example"}},
+		{"s", "buſ", 0, []int{4}, []string{"bu"}},
+		{"ab", "xAbab", 0, []int{3, 5}, []string{"xAb"}},
+	} {
+		m := NewSuffixAny(test.suffix, []rune{'/'})
+		m.Fold = true
+
+		index, segments := m.Index(test.fixture)
+		if index != test.index {
+			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
+		}
+		if !reflect.DeepEqual(segments, test.segments) {
+			t.Errorf("#%d unexpected segments: exp: %v, act: %v", id, test.segments, segments)
+		}
+
+		captures, _ := m.Capture(test.fixture, nil)
+		if !reflect.DeepEqual(captures, test.captures) {
+			t.Errorf("#%d unexpected capture: exp: %q, act: %q", id, test.captures, captures)
+		}
+	}
+}
+
+func TestSuffixAnyCouldMatchPrefix(t *testing.T) {
+	m := NewSuffixAny("/x.go", []rune{'/'})
+	for id, test := range []struct {
+		prefix string
+		should bool
+	}{
+		{"", true},
+		{"pkg", true},
+		{"pkg/", true},
+		{"pkg/x", true},
+		{"pkg/y", false},
+		{"pkg/x.go", true},
+		{"pkg/x.go/", false},
+		{"a/b", false},
+	} {
+		if act := m.CouldMatchPrefix(test.prefix); act != test.should {
+			t.Errorf("#%d CouldMatchPrefix(%q) = %v; want %v", id, test.prefix, act, test.should)
+		}
+	}
+}
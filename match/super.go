@@ -0,0 +1,45 @@
+package match
+
+// Super represents any sequence of characters including separators.
+type Super struct{}
+
+func NewSuper() Super {
+	return Super{}
+}
+
+func (s Super) Match(_ string) bool {
+	return true
+}
+
+func (s Super) MatchRune(_ rune) bool {
+	return true
+}
+
+func (s Super) Len() int {
+	return lenNo
+}
+
+func (s Super) Index(str string) (int, []int) {
+	segments := acquireSegments(len(str) + 1)
+	for i := range str {
+		segments = append(segments, i)
+	}
+	segments = append(segments, len(str))
+
+	return 0, segments
+}
+
+func (s Super) String() string {
+	return "<super>"
+}
+
+func (s Super) Capture(str string, dst []string) ([]string, bool) {
+	if !s.Match(str) {
+		return dst, false
+	}
+	return append(dst, str), true
+}
+
+func (s Super) CouldMatchPrefix(_ string) bool {
+	return true
+}
@@ -4,14 +4,18 @@ import (
 	"fmt"
 	"strings"
 	"unicode/utf8"
+
+	sutil "github.com/gopherlib/simple-glob/util/strings"
 )
 
-// Text raw represents raw string to match
+// Text raw represents raw string to match.
+// If Fold is set, strings are compared under Unicode simple case folding.
 type Text struct {
 	Str         string
 	RunesLength int
 	BytesLength int
 	Segments    []int
+	Fold        bool
 }
 
 func NewText(s string) Text {
@@ -23,7 +27,16 @@ func NewText(s string) Text {
 	}
 }
 
+// withFold returns copy of t with the given Fold.
+func (t Text) withFold(fold bool) Text {
+	t.Fold = fold
+	return t
+}
+
 func (t Text) Match(s string) bool {
+	if t.Fold {
+		return strings.EqualFold(t.Str, s)
+	}
 	return t.Str == s
 }
 
@@ -32,6 +45,17 @@ func (t Text) Len() int {
 }
 
 func (t Text) Index(s string) (int, []int) {
+	if t.Fold {
+		index, length := sutil.IndexFold(s, t.Str)
+		if index == -1 {
+			return -1, nil
+		}
+		if length != t.BytesLength {
+			return index, []int{length}
+		}
+		return index, t.Segments
+	}
+
 	index := strings.Index(s, t.Str)
 	if index == -1 {
 		return -1, nil
@@ -41,5 +65,17 @@ func (t Text) Index(s string) (int, []int) {
 }
 
 func (t Text) String() string {
-	return fmt.Sprintf("<text:`%v`>", t.Str)
+	return fmt.Sprintf("<text:`%v`%s>", t.Str, foldFlag(t.Fold))
+}
+
+func (t Text) Capture(s string, dst []string) ([]string, bool) {
+	return dst, t.Match(s)
+}
+
+func (t Text) CouldMatchPrefix(s string) bool {
+	if t.Fold {
+		_, ok := sutil.HasPrefixFold(t.Str, s)
+		return ok
+	}
+	return strings.HasPrefix(t.Str, s)
 }
@@ -8,24 +8,49 @@ import (
 func TestTextIndex(t *testing.T) {
 	for id, test := range []struct {
 		text     string
+		fold     bool
 		fixture  string
 		index    int
 		segments []int
 	}{
 		{
 			"b",
+			false,
 			"abc",
 			1,
 			[]int{1},
 		},
 		{
 			"f",
+			false,
 			"abcd",
 			-1,
 			nil,
 		},
+		{
+			"B",
+			false,
+			"abc",
+			-1,
+			nil,
+		},
+		{
+			"B",
+			true,
+			"abc",
+			1,
+			[]int{1},
+		},
+		{
+			"ss",
+			true,
+			"maſs",
+			2,
+			[]int{3},
+		},
 	} {
 		m := NewText(test.text)
+		m.Fold = test.fold
 		index, segments := m.Index(test.fixture)
 		if index != test.index {
 			t.Errorf("#%d unexpected index: exp: %d, act: %d", id, test.index, index)
@@ -0,0 +1,112 @@
+package glob
+
+import (
+	"github.com/gopherlib/simple-glob/compiler"
+	"github.com/gopherlib/simple-glob/syntax"
+	"github.com/gopherlib/simple-glob/syntax/ast"
+)
+
+// Option configures compilation of a pattern by CompileOptions.
+type Option func(*options)
+
+type options struct {
+	separators       []rune
+	features         Feature
+	maxPatternLength int
+	maxWildcards     int
+	maxDepth         int
+	maxInputLength   int
+	shortest         bool
+}
+
+// WithSeparators sets characters that are not matched by `*`, `?` and negated classes.
+func WithSeparators(separators ...rune) Option {
+	return func(o *options) {
+		o.separators = append(o.separators, separators...)
+	}
+}
+
+// WithFeatures enables the given syntax features and behaviors.
+func WithFeatures(features Feature) Option {
+	return func(o *options) {
+		o.features |= features
+	}
+}
+
+// WithCaseFold makes the pattern match case-insensitively. It is the same as WithFeatures(CaseFold).
+func WithCaseFold() Option {
+	return WithFeatures(CaseFold)
+}
+
+// WithShortest makes Find methods of the compiled glob prefer the shortest match
+// at the leftmost position. By default the longest one is preferred.
+func WithShortest() Option {
+	return func(o *options) {
+		o.shortest = true
+	}
+}
+
+// WithMaxPatternLength limits the pattern length in bytes.
+// Compilation of a longer pattern fails with *LimitError. Zero means no limit.
+func WithMaxPatternLength(n int) Option {
+	return func(o *options) {
+		o.maxPatternLength = n
+	}
+}
+
+// WithMaxWildcards limits the number of wildcards and character classes in the pattern.
+// Compilation of a pattern with more of them fails with *LimitError. Zero means no limit.
+func WithMaxWildcards(n int) Option {
+	return func(o *options) {
+		o.maxWildcards = n
+	}
+}
+
+// WithMaxDepth limits the number of wildcards and character classes in a row in the pattern,
+// counting only the deepest alternative of each alternation, which bounds the depth of
+// the matcher tree the pattern is compiled to. Compilation of a deeper pattern fails
+// with *LimitError before the tree is built. Zero means no limit.
+func WithMaxDepth(n int) Option {
+	return func(o *options) {
+		o.maxDepth = n
+	}
+}
+
+// WithMaxInputLength limits the length in bytes of strings the compiled glob matches or searches.
+// Longer strings are never matched. Zero means no limit.
+func WithMaxInputLength(n int) Option {
+	return func(o *options) {
+		o.maxInputLength = n
+	}
+}
+
+// Limits applied by WithSafeLimits.
+const (
+	SafeMaxPatternLength = 1024
+	SafeMaxWildcards     = 32
+	SafeMaxDepth         = 32
+	SafeMaxInputLength   = 64 << 10
+)
+
+// WithSafeLimits limits complexity of patterns and length of inputs,
+// so patterns from untrusted sources could be compiled and matched in bounded time.
+// Limits given by other options after it take precedence.
+func WithSafeLimits() Option {
+	return func(o *options) {
+		o.maxPatternLength = SafeMaxPatternLength
+		o.maxWildcards = SafeMaxWildcards
+		o.maxDepth = SafeMaxDepth
+		o.maxInputLength = SafeMaxInputLength
+	}
+}
+
+// LimitError is returned when a pattern exceeds a complexity limit.
+type LimitError = compiler.LimitError
+
+// parse checks the pattern length and parses it with enabled features.
+func (o options) parse(pattern string) (*ast.Node, error) {
+	if err := compiler.CheckPatternLength(pattern, o.maxPatternLength); err != nil {
+		return nil, err
+	}
+	return syntax.ParseMode(pattern, o.features.lexerMode())
+}
@@ -0,0 +1,99 @@
+package glob
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gopherlib/simple-glob/compiler"
+)
+
+func TestCompileOptions(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		opts    []Option
+		fixture string
+		should  bool
+	}{
+		{pattern: "*.com", fixture: "a.b.com", should: true},
+		{pattern: "*.com", opts: []Option{WithSeparators('.')}, fixture: "a.b.com", should: false},
+		{pattern: "*.com", opts: []Option{WithSeparators('/'), WithSeparators('.')}, fixture: "a.b.com", should: false},
+		{pattern: "a?c", fixture: "abc", should: false},
+		{pattern: "a?c", opts: []Option{WithFeatures(Single)}, fixture: "abc", should: true},
+		{pattern: "[a-c]?", opts: []Option{WithFeatures(Single), WithFeatures(Classes)}, fixture: "bx", should: true},
+		{pattern: "*.COM", opts: []Option{WithCaseFold()}, fixture: "a.com", should: true},
+		{pattern: "*.COM", opts: []Option{WithCaseFold(), WithSeparators('.')}, fixture: "a.b.com", should: false},
+		{pattern: "abc", opts: []Option{WithMaxPatternLength(3)}, fixture: "abc", should: true},
+	} {
+		g, err := CompileOptions(test.pattern, test.opts...)
+		if err != nil {
+			t.Errorf("pattern %q: unexpected error: %s", test.pattern, err)
+			continue
+		}
+		if act := g.Match(test.fixture); act != test.should {
+			t.Errorf("pattern %q matching %q should be %v but got %v\n%s", test.pattern, test.fixture, test.should, act, g)
+		}
+	}
+}
+
+func TestCompileOptionsMaxPatternLength(t *testing.T) {
+	pattern := strings.Repeat("a*", 10)
+	if _, err := CompileOptions(pattern, WithMaxPatternLength(len(pattern)-1)); err == nil {
+		t.Errorf("expected error for pattern %q longer than limit", pattern)
+	} else if err.Error() != "pattern length 20 exceeds limit of 19" {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if _, err := CompileOptions(pattern, WithMaxPatternLength(len(pattern))); err != nil {
+		t.Errorf("unexpected error for pattern %q: %s", pattern, err)
+	}
+}
+
+func TestCompileOptionsLimits(t *testing.T) {
+	for _, test := range []struct {
+		pattern string
+		opts    []Option
+		limit   compiler.Limit
+	}{
+		{pattern: "a*b*c", opts: []Option{WithMaxWildcards(2)}},
+		{pattern: "a*b*c*", opts: []Option{WithMaxWildcards(2)}, limit: compiler.LimitWildcards},
+		{pattern: "a?[bc]*", opts: []Option{WithMaxWildcards(2), WithFeatures(Single | Classes)}, limit: compiler.LimitWildcards},
+		{pattern: "*a*b*c", opts: []Option{WithMaxDepth(3)}},
+		{pattern: "*a*b*c*d", opts: []Option{WithMaxDepth(3)}, limit: compiler.LimitDepth},
+		{pattern: "{*a*,*b*}*", opts: []Option{WithMaxDepth(3), WithMaxWildcards(5), WithFeatures(Alternates)}},
+		{pattern: "{*a*b*,c}*", opts: []Option{WithMaxDepth(3), WithFeatures(Alternates)}, limit: compiler.LimitDepth},
+		{pattern: strings.Repeat("*a", 1000), opts: []Option{WithMaxDepth(32)}, limit: compiler.LimitDepth},
+		{pattern: strings.Repeat("*", 100), opts: []Option{WithSafeLimits()}, limit: compiler.LimitWildcards},
+		{pattern: strings.Repeat("a", 2000), opts: []Option{WithSafeLimits()}, limit: compiler.LimitPatternLength},
+		{pattern: strings.Repeat("a", 2000), opts: []Option{WithSafeLimits(), WithMaxPatternLength(0)}},
+	} {
+		_, err := CompileOptions(test.pattern, test.opts...)
+		if test.limit == 0 {
+			if err != nil {
+				t.Errorf("pattern %q: unexpected error: %s", test.pattern, err)
+			}
+			continue
+		}
+
+		var lerr *LimitError
+		if !errors.As(err, &lerr) {
+			t.Errorf("pattern %q: expected *LimitError, got %v", test.pattern, err)
+			continue
+		}
+		if lerr.Limit != test.limit || lerr.Value <= lerr.Max {
+			t.Errorf("pattern %q: unexpected error: %s", test.pattern, err)
+		}
+	}
+}
+
+func TestCompileOptionsMaxInputLength(t *testing.T) {
+	g := MustCompileOptions("*.log", WithMaxInputLength(8))
+	if !g.Match("app.log") {
+		t.Errorf("expected %q to match", "app.log")
+	}
+	if g.Match("large.log") {
+		t.Errorf("expected %q longer than limit not to match", "large.log")
+	}
+	if loc := g.FindStringIndex("see the app.log"); loc != nil {
+		t.Errorf("expected no match in text longer than limit, got %v", loc)
+	}
+}
@@ -0,0 +1,8 @@
+//go:build race
+// +build race
+
+package glob
+
+// raceEnabled is set when tests run with the race detector,
+// which makes sync.Pool drop pooled values at random.
+const raceEnabled = true
@@ -0,0 +1,149 @@
+package glob
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/gopherlib/simple-glob/syntax/ast"
+)
+
+// ToRegexp converts the pattern into an equivalent anchored regular expression
+// in RE2 syntax, as accepted by regexp package. The pattern is parsed with the
+// given options, so `*` becomes `[^./]*` when `.` and `/` are separators, and
+// CaseFold feature becomes `(?i)` flag.
+func ToRegexp(pattern string, opts ...Option) (string, error) {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	tree, err := o.parse(pattern)
+	if err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+	b.WriteString("^(?s")
+	if o.features&CaseFold != 0 {
+		b.WriteString("i")
+	}
+	b.WriteString(":")
+	if err := writeRegexp(&b, tree, o.separators); err != nil {
+		return "", err
+	}
+	b.WriteString(")$")
+
+	return b.String(), nil
+}
+
+func writeRegexp(b *strings.Builder, tree *ast.Node, sep []rune) error {
+	switch tree.Kind {
+	case ast.KindPattern:
+		for _, c := range tree.Children {
+			if err := writeRegexp(b, c, sep); err != nil {
+				return err
+			}
+		}
+
+	case ast.KindAnyOf:
+		b.WriteString("(?:")
+		for i, c := range tree.Children {
+			if i > 0 {
+				b.WriteString("|")
+			}
+			if err := writeRegexp(b, c, sep); err != nil {
+				return err
+			}
+		}
+		b.WriteString(")")
+
+	case ast.KindText:
+		b.WriteString(regexp.QuoteMeta(tree.Value.(ast.Text).Text))
+
+	case ast.KindAny:
+		writeNotSeparator(b, sep)
+		b.WriteString("*")
+
+	case ast.KindSuper:
+		b.WriteString(".*")
+
+	case ast.KindSingle:
+		writeNotSeparator(b, sep)
+
+	case ast.KindList, ast.KindRange, ast.KindClass:
+		writeClass(b, tree, sep)
+
+	case ast.KindNothing:
+
+	default:
+		return fmt.Errorf("could not convert tree to regexp: unknown node type")
+	}
+
+	return nil
+}
+
+// writeNotSeparator writes regexp matching any single character except separators.
+func writeNotSeparator(b *strings.Builder, sep []rune) {
+	if len(sep) == 0 {
+		b.WriteString(".")
+		return
+	}
+	b.WriteString("[^")
+	for _, r := range sep {
+		writeClassRune(b, r)
+	}
+	b.WriteString("]")
+}
+
+// writeClass writes regexp matching a single character of the list, range or class node.
+func writeClass(b *strings.Builder, tree *ast.Node, sep []rune) {
+	var not bool
+	items := []*ast.Node{tree}
+	switch v := tree.Value.(type) {
+	case ast.List:
+		not = v.Not
+	case ast.Range:
+		not = v.Not
+	case ast.Class:
+		not = v.Not
+		items = tree.Children
+	}
+
+	b.WriteString("[")
+	if not {
+		b.WriteString("^")
+	}
+	for _, item := range items {
+		switch v := item.Value.(type) {
+		case ast.List:
+			for _, r := range v.Chars {
+				writeClassRune(b, r)
+			}
+		case ast.Range:
+			writeClassRune(b, v.Lo)
+			b.WriteString("-")
+			writeClassRune(b, v.Hi)
+		}
+	}
+	if not {
+		// separators are never matched by negated class
+		for _, r := range sep {
+			writeClassRune(b, r)
+		}
+	}
+	b.WriteString("]")
+}
+
+// writeClassRune writes the rune escaped to be used inside of a character class.
+func writeClassRune(b *strings.Builder, r rune) {
+	switch {
+	case r < ' ' || r == 0x7f:
+		fmt.Fprintf(b, `\x{%x}`, r)
+	case strings.ContainsRune(`\[]^-`, r):
+		b.WriteByte('\\')
+		b.WriteRune(r)
+	default:
+		b.WriteRune(r)
+	}
+}
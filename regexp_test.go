@@ -0,0 +1,111 @@
+package glob
+
+import (
+	"math/rand"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestToRegexp(t *testing.T) {
+	for _, test := range []struct {
+		pattern    string
+		features   Feature
+		delimiters []rune
+		exp        string
+	}{
+		{pattern: "abc", exp: `^(?s:abc)$`},
+		{pattern: "*.example.com", delimiters: []rune{'.', '/'}, exp: `^(?s:[^./]*\.example\.com)$`},
+		{pattern: "*.com", exp: `^(?s:.*\.com)$`},
+		{pattern: "a?c", features: Single, delimiters: []rune{'.'}, exp: `^(?s:a[^.]c)$`},
+		{pattern: "/**/*.go", features: Super, delimiters: []rune{'/'}, exp: `^(?s:/.*/[^/]*\.go)$`},
+		{pattern: "[!a-c][xyz]", features: Classes, delimiters: []rune{'.'}, exp: `^(?s:[^a-c.][xyz])$`},
+		{pattern: `[\]^-]`, features: Classes | Escape, exp: `^(?s:[\]\^\-])$`},
+		{pattern: "{api,web}.*", features: Alternates, exp: `^(?s:(?:api|web)\..*)$`},
+		{pattern: "ABC", features: CaseFold, exp: `^(?si:ABC)$`},
+	} {
+		act, err := ToRegexp(test.pattern, WithFeatures(test.features), WithSeparators(test.delimiters...))
+		if err != nil {
+			t.Errorf("pattern %q: unexpected error: %s", test.pattern, err)
+			continue
+		}
+		if act != test.exp {
+			t.Errorf("pattern %q: unexpected regexp: exp: %s, act: %s", test.pattern, test.exp, act)
+		}
+	}
+}
+
+func TestToRegexpDifferential(t *testing.T) {
+	for _, test := range globTests {
+		expr, err := ToRegexp(test.pattern, WithFeatures(test.features), WithSeparators(test.delimiters...))
+		if err != nil {
+			t.Errorf("pattern %q: unexpected error: %s", test.pattern, err)
+			continue
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			t.Errorf("pattern %q: invalid regexp %s: %s", test.pattern, expr, err)
+			continue
+		}
+		if act := re.MatchString(test.match); act != test.should {
+			t.Errorf("pattern %q as %s matching %q should be %v but got %v", test.pattern, expr, test.match, test.should, act)
+		}
+	}
+}
+
+// TestRandomDifferential compares random patterns with their regular expressions,
+// and Match with Captures and FindStringIndex of the same glob.
+func TestRandomDifferential(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	atoms := []string{"a", "b", ".", "*", "**", "?", "[ab]", "[!a]", "[.a-b]", "[!b-c.]", "{a,}", "{,b}", "{a,b.}", "{a*,b}"}
+
+	for i := 0; i < 5000; i++ {
+		var pattern strings.Builder
+		for n := rnd.Intn(5); n >= 0; n-- {
+			pattern.WriteString(atoms[rnd.Intn(len(atoms))])
+		}
+		opts := []Option{WithFeatures(Single | Super | Classes | Alternates)}
+		if rnd.Intn(2) == 0 {
+			opts = append(opts, WithSeparators('.'))
+		}
+		if rnd.Intn(4) == 0 {
+			opts = append(opts, WithCaseFold())
+		}
+
+		g, err := CompileOptions(pattern.String(), opts...)
+		if err != nil {
+			t.Fatalf("pattern %q: unexpected error: %s", pattern.String(), err)
+		}
+		expr, err := ToRegexp(pattern.String(), opts...)
+		if err != nil {
+			t.Fatalf("pattern %q: unexpected error: %s", pattern.String(), err)
+		}
+		re := regexp.MustCompile(expr)
+
+		for j := 0; j < 10; j++ {
+			var b strings.Builder
+			for n := rnd.Intn(6); n > 0; n-- {
+				b.WriteByte("abAB."[rnd.Intn(5)])
+			}
+			s := b.String()
+
+			match := g.Match(s)
+			if exp := re.MatchString(s); match != exp {
+				t.Errorf("pattern %q matching %q should be %v as %s but got %v\n%s", pattern.String(), s, exp, expr, match, g)
+			}
+			if _, ok := g.Captures(s); ok != match {
+				t.Errorf("pattern %q captures in %q reports %v while match is %v\n%s", pattern.String(), s, ok, match, g)
+			}
+			if loc, exp := g.FindStringIndex(s), bruteForceFind(g, s, false); !reflect.DeepEqual(loc, exp) {
+				t.Errorf("pattern %q find in %q should be %v but got %v\n%s", pattern.String(), s, exp, loc, g)
+			}
+		}
+	}
+}
+
+func TestToRegexpError(t *testing.T) {
+	if _, err := ToRegexp("[a", WithFeatures(Classes)); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+}
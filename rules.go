@@ -0,0 +1,135 @@
+package glob
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Order defines which of several matching rules decides the outcome.
+type Order int
+
+const (
+	// LastMatchWins makes the last matching rule decide, as in .gitignore files.
+	LastMatchWins Order = iota
+	// FirstMatchWins makes the first matching rule decide.
+	FirstMatchWins
+)
+
+// Rule is a single include or exclude rule of Rules.
+type Rule struct {
+	// Pattern is the glob pattern of the rule, without leading `!`.
+	Pattern string
+	// Exclude reports whether the rule was given as `!pattern`.
+	Exclude bool
+	// Line is the 1-based number of the line the rule was given on.
+	Line int
+}
+
+func (r Rule) String() string {
+	if r.Exclude {
+		return fmt.Sprintf("%d:!%s", r.Line, r.Pattern)
+	}
+	return fmt.Sprintf("%d:%s", r.Line, r.Pattern)
+}
+
+// Rules is an ordered list of include and exclude rules.
+type Rules struct {
+	rules []Rule
+	set   *GlobSet
+	order Order
+}
+
+// CompileRules compiles rules given one per line.
+//
+// A line `pattern` includes strings matching the pattern, and a line `!pattern`
+// excludes them. Empty lines and lines starting with `#` are ignored.
+// A leading `\` is removed from a line, so `\!pattern` and `\#pattern`
+// match strings starting with `!` and `#`.
+//
+// An error of an invalid pattern reports its line, and positions in
+// *SyntaxError refer to the line itself.
+func CompileRules(lines []string, order Order, opts ...Option) (*Rules, error) {
+	var (
+		rules    []Rule
+		patterns []string
+	)
+	for i, line := range lines {
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		rule := Rule{Line: i + 1}
+		switch {
+		case line[0] == '!':
+			rule.Exclude = true
+			line = line[1:]
+		case strings.HasPrefix(line, `\!`), strings.HasPrefix(line, `\#`):
+			line = line[1:]
+		}
+		rule.Pattern = line
+
+		rules = append(rules, rule)
+		patterns = append(patterns, line)
+	}
+
+	set, i, err := compileGlobSet(patterns, opts)
+	if err != nil {
+		line := lines[rules[i].Line-1]
+		return nil, fmt.Errorf("line %d %q: %w", rules[i].Line, line, lineSyntaxError(err, line))
+	}
+
+	return &Rules{
+		rules: rules,
+		set:   set,
+		order: order,
+	}, nil
+}
+
+// lineSyntaxError returns err with position of *SyntaxError moved
+// from the pattern to the line it was given on.
+func lineSyntaxError(err error, line string) error {
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		return err
+	}
+	e := *se
+	e.Pattern = line
+	// the pattern is the end of the line
+	e.Offset += len(line) - len(se.Pattern)
+	e.Column = utf8.RuneCountInString(line[:e.Offset]) + 1
+	return &e
+}
+
+// MustCompileRules is the same as CompileRules, except that if CompileRules returns error, this will panic
+func MustCompileRules(lines []string, order Order, opts ...Option) *Rules {
+	r, err := CompileRules(lines, order, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	return r
+}
+
+// Rules returns the compiled rules in order.
+func (r *Rules) Rules() []Rule {
+	return append([]Rule(nil), r.rules...)
+}
+
+// Decide returns the rule that decides whether s is included.
+// It returns false if no rule matches s.
+func (r *Rules) Decide(s string) (Rule, bool) {
+	i := r.set.first(s, r.order == LastMatchWins)
+	if i == -1 {
+		return Rule{}, false
+	}
+	return r.rules[i], true
+}
+
+// Included reports whether s is included by the rules.
+// A string that no rule matches is not included.
+func (r *Rules) Included(s string) bool {
+	rule, ok := r.Decide(s)
+	return ok && !rule.Exclude
+}
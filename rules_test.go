@@ -0,0 +1,78 @@
+package glob
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	lines := []string{
+		"# logs",
+		"*.log",
+		"!important.log",
+		"",
+		"debug*",
+		`\!bang`,
+		`\#hash`,
+	}
+	for _, test := range []struct {
+		order    Order
+		fixture  string
+		included bool
+		line     int
+		decided  bool
+	}{
+		{order: LastMatchWins, fixture: "app.log", included: true, line: 2, decided: true},
+		{order: LastMatchWins, fixture: "important.log", included: false, line: 3, decided: true},
+		{order: LastMatchWins, fixture: "readme.md", included: false, decided: false},
+		{order: LastMatchWins, fixture: "debug.log", included: true, line: 5, decided: true},
+		{order: LastMatchWins, fixture: "!bang", included: true, line: 6, decided: true},
+		{order: LastMatchWins, fixture: "#hash", included: true, line: 7, decided: true},
+		{order: LastMatchWins, fixture: "# logs", included: false, decided: false},
+		{order: FirstMatchWins, fixture: "important.log", included: true, line: 2, decided: true},
+		{order: FirstMatchWins, fixture: "debug.log", included: true, line: 2, decided: true},
+		{order: FirstMatchWins, fixture: "debug.txt", included: true, line: 5, decided: true},
+	} {
+		rules := MustCompileRules(lines, test.order, WithSeparators('/'))
+
+		rule, ok := rules.Decide(test.fixture)
+		if ok != test.decided || rule.Line != test.line {
+			t.Errorf("%q: unexpected deciding rule: exp: line %d %v, act: %v %v", test.fixture, test.line, test.decided, rule, ok)
+		}
+		if act := rules.Included(test.fixture); act != test.included {
+			t.Errorf("%q: unexpected included: exp: %v, act: %v", test.fixture, test.included, act)
+		}
+	}
+}
+
+func TestRulesParse(t *testing.T) {
+	rules := MustCompileRules([]string{"a", "!b", "#c", `\!d`}, LastMatchWins)
+	exp := []Rule{
+		{Pattern: "a", Line: 1},
+		{Pattern: "b", Exclude: true, Line: 2},
+		{Pattern: "!d", Line: 4},
+	}
+	if act := rules.Rules(); !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected rules: exp: %v, act: %v", exp, act)
+	}
+}
+
+func TestRulesError(t *testing.T) {
+	if _, err := CompileRules([]string{"!{a"}, LastMatchWins, WithFeatures(Alternates)); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+
+	_, err := CompileRules([]string{"# comment", "*.go", "", "!a[b"}, LastMatchWins, WithFeatures(Classes))
+	var se *SyntaxError
+	if !errors.As(err, &se) {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.HasPrefix(err.Error(), `line 4 "!a[b": `) {
+		t.Errorf("unexpected error: %s", err)
+	}
+	if se.Pattern != "!a[b" || se.Offset != 2 || se.Column != 3 {
+		t.Errorf("unexpected error position: exp: %q offset 2 column 3, act: %q offset %d column %d", "!a[b", se.Pattern, se.Offset, se.Column)
+	}
+}
@@ -0,0 +1,140 @@
+package glob
+
+import (
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/match"
+)
+
+// Specificity describes how specific a compiled pattern is.
+// It is used by GlobSet.Best to choose the most specific of several matching patterns.
+type Specificity struct {
+	// Literals is the number of runes matched literally.
+	Literals int
+	// Wildcards is the number of wildcards matching a sequence of characters, like `*` and `**`.
+	Wildcards int
+	// Crossing is the number of those wildcards that could match separators.
+	Crossing int
+	// Singles is the number of wildcards matching exactly one character, like `?` and classes.
+	Singles int
+}
+
+// Compare returns -1 if s is less specific than other, +1 if s is more specific, and 0 otherwise.
+// The pattern with more literal runes is more specific; among patterns with the
+// same number of literal runes the one with fewer wildcards is more specific, then
+// the one with fewer wildcards matching separators, then the one with fewer
+// single character wildcards.
+func (s Specificity) Compare(other Specificity) int {
+	switch {
+	case s.Literals != other.Literals:
+		return compareInt(s.Literals, other.Literals)
+	case s.Wildcards != other.Wildcards:
+		return compareInt(other.Wildcards, s.Wildcards)
+	case s.Crossing != other.Crossing:
+		return compareInt(other.Crossing, s.Crossing)
+	default:
+		return compareInt(other.Singles, s.Singles)
+	}
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (s Specificity) add(other Specificity) Specificity {
+	return Specificity{
+		Literals:  s.Literals + other.Literals,
+		Wildcards: s.Wildcards + other.Wildcards,
+		Crossing:  s.Crossing + other.Crossing,
+		Singles:   s.Singles + other.Singles,
+	}
+}
+
+// specificity computes specificity of the matcher tree.
+// Alternation is as specific as its least specific alternative.
+func specificity(m match.Matcher) (s Specificity) {
+	switch m := m.(type) {
+	case match.Text:
+		s.Literals = m.RunesLength
+
+	case match.Any:
+		s.Wildcards = 1
+		if len(m.Separators) == 0 {
+			s.Crossing = 1
+		}
+
+	case match.Super:
+		s.Wildcards = 1
+		s.Crossing = 1
+
+	case match.Single, match.List, match.Range, match.Class:
+		s.Singles = 1
+
+	case match.PrefixAny:
+		s.Literals = utf8.RuneCountInString(m.Prefix)
+		s.Wildcards = 1
+		if len(m.Separators) == 0 {
+			s.Crossing = 1
+		}
+
+	case match.SuffixAny:
+		s.Literals = utf8.RuneCountInString(m.Suffix)
+		s.Wildcards = 1
+		if len(m.Separators) == 0 {
+			s.Crossing = 1
+		}
+
+	case match.PrefixSuffix:
+		s.Literals = utf8.RuneCountInString(m.Prefix) + utf8.RuneCountInString(m.Suffix)
+		s.Wildcards = 1
+		s.Crossing = 1
+
+	case match.DFA:
+		s = specificity(m.Fallback)
+
+	case match.LengthLimit:
+		s = specificity(m.Matcher)
+
+	case match.Contains:
+		s.Literals = utf8.RuneCountInString(m.Needle)
+		s.Wildcards = 2
+		s.Crossing = 2
+
+	case match.Substrings:
+		for _, str := range m.Strs {
+			s.Literals += utf8.RuneCountInString(str)
+		}
+		s.Wildcards = len(m.Strs) + 1
+		s.Crossing = len(m.Strs) + 1
+
+	case match.Row:
+		for _, c := range m.Matchers {
+			s = s.add(specificity(c))
+		}
+
+	case match.BTree:
+		s = specificity(m.Value)
+		if m.Left != nil {
+			s = s.add(specificity(m.Left))
+		}
+		if m.Right != nil {
+			s = s.add(specificity(m.Right))
+		}
+
+	case match.AnyOf:
+		for i, c := range m.Matchers {
+			if cs := specificity(c); i == 0 || cs.Compare(s) < 0 {
+				s = cs
+			}
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,50 @@
+package glob
+
+import (
+	"testing"
+)
+
+func TestSpecificity(t *testing.T) {
+	for _, test := range []struct {
+		pattern     string
+		features    Feature
+		delimiters  []rune
+		specificity Specificity
+	}{
+		{pattern: "api.example.com", specificity: Specificity{Literals: 15}},
+		{pattern: "*.example.com", specificity: Specificity{Literals: 12, Wildcards: 1, Crossing: 1}},
+		{pattern: "*.example.com", delimiters: []rune{'.'}, specificity: Specificity{Literals: 12, Wildcards: 1}},
+		{pattern: "**.example.com", delimiters: []rune{'.'}, features: Super, specificity: Specificity{Literals: 12, Wildcards: 1, Crossing: 1}},
+		{pattern: "a*b*c", delimiters: []rune{'.'}, specificity: Specificity{Literals: 3, Wildcards: 2}},
+		{pattern: "?.日本", features: Single, specificity: Specificity{Literals: 3, Singles: 1}},
+		{pattern: "[a-z]x[abc]", features: Classes, specificity: Specificity{Literals: 1, Singles: 2}},
+		{pattern: "x{abc,*}", features: Alternates, specificity: Specificity{Literals: 1, Wildcards: 1, Crossing: 1}},
+		{pattern: "ab*cd", specificity: Specificity{Literals: 4, Wildcards: 1, Crossing: 1}},
+		{pattern: "*abc*", specificity: Specificity{Literals: 3, Wildcards: 2, Crossing: 2}},
+		{pattern: "*ab*cd*", specificity: Specificity{Literals: 4, Wildcards: 3, Crossing: 3}},
+	} {
+		g := MustCompileFeatures(test.pattern, test.features, test.delimiters...).(glob)
+		if act := specificity(g.matcher); act != test.specificity {
+			t.Errorf("pattern %q: unexpected specificity: exp: %+v, act: %+v\n%s", test.pattern, test.specificity, act, g)
+		}
+	}
+}
+
+func TestSpecificityCompare(t *testing.T) {
+	for id, test := range []struct {
+		a, b Specificity
+		exp  int
+	}{
+		{Specificity{Literals: 2}, Specificity{Literals: 1, Singles: 1}, 1},
+		{Specificity{Literals: 2, Wildcards: 1}, Specificity{Literals: 2, Singles: 3}, -1},
+		{Specificity{Literals: 2, Wildcards: 1}, Specificity{Literals: 2, Wildcards: 1, Crossing: 1}, 1},
+		{Specificity{Literals: 2, Singles: 1}, Specificity{Literals: 2, Singles: 1}, 0},
+	} {
+		if act := test.a.Compare(test.b); act != test.exp {
+			t.Errorf("#%d %+v.Compare(%+v) = %d; want %d", id, test.a, test.b, act, test.exp)
+		}
+		if act := test.b.Compare(test.a); act != -test.exp {
+			t.Errorf("#%d %+v.Compare(%+v) = %d; want %d", id, test.b, test.a, act, -test.exp)
+		}
+	}
+}
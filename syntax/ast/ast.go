@@ -10,6 +10,8 @@ type Node struct {
 	Children []*Node
 	Value    interface{}
 	Kind     Kind
+	// Pos is the byte offset of the node in the pattern.
+	Pos int
 }
 
 func NewNode(k Kind, v interface{}, ch ...*Node) *Node {
@@ -78,6 +80,12 @@ type Range struct {
 	Lo, Hi rune
 }
 
+// Class is the value of KindClass node, a union of its KindList and
+// KindRange children, such as `[a-z0-9_]`. Not of the children is unused.
+type Class struct {
+	Not bool
+}
+
 type Text struct {
 	Text string
 }
@@ -94,6 +102,7 @@ const (
 	KindSuper
 	KindSingle
 	KindAnyOf
+	KindClass
 )
 
 func (k Kind) String() string {
@@ -110,8 +119,14 @@ func (k Kind) String() string {
 		return "Text"
 	case KindAny:
 		return "Any"
+	case KindSuper:
+		return "Super"
+	case KindSingle:
+		return "Single"
 	case KindAnyOf:
 		return "AnyOf"
+	case KindClass:
+		return "Class"
 	default:
 		return ""
 	}
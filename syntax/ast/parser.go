@@ -1,8 +1,8 @@
 package ast
 
 import (
-	"errors"
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/gopherlib/simple-glob/syntax/lexer"
 )
@@ -13,6 +13,31 @@ type Lexer interface {
 
 type parseFn func(*Node, Lexer) (parseFn, *Node, error)
 
+// Error is an error found while parsing the token.
+type Error struct {
+	Token lexer.Token
+	Msg   string
+}
+
+func (e *Error) Error() string {
+	return e.Msg
+}
+
+func errorf(token lexer.Token, f string, v ...interface{}) *Error {
+	return &Error{Token: token, Msg: fmt.Sprintf(f, v...)}
+}
+
+// tokenError returns the error reported by the lexer in the Error token.
+func tokenError(token lexer.Token) *Error {
+	return &Error{Token: token, Msg: token.Raw}
+}
+
+func newNodeAt(token lexer.Token, k Kind, v interface{}, ch ...*Node) *Node {
+	n := NewNode(k, v, ch...)
+	n.Pos = token.Pos
+	return n
+}
+
 func Parse(lexer Lexer) (*Node, error) {
 	var parser parseFn
 
@@ -40,26 +65,137 @@ func parserMain(tree *Node, lex Lexer) (parseFn, *Node, error) {
 			return nil, tree, nil
 
 		case lexer.Error:
-			return nil, tree, errors.New(token.Raw)
+			return nil, tree, tokenError(token)
 
 		case lexer.Text:
-			Insert(tree, NewNode(KindText, Text{token.Raw}))
+			Insert(tree, newNodeAt(token, KindText, Text{token.Raw}))
 			return parserMain, tree, nil
 
 		case lexer.Any:
-			Insert(tree, NewNode(KindAny, nil))
+			Insert(tree, newNodeAt(token, KindAny, nil))
+			return parserMain, tree, nil
+
+		case lexer.Super:
+			Insert(tree, newNodeAt(token, KindSuper, nil))
+			return parserMain, tree, nil
+
+		case lexer.Single:
+			Insert(tree, newNodeAt(token, KindSingle, nil))
 			return parserMain, tree, nil
 
+		case lexer.RangeOpen:
+			return parserRange(token), tree, nil
+
+		case lexer.TermsOpen:
+			a := newNodeAt(token, KindAnyOf, nil)
+			Insert(tree, a)
+
+			p := newNodeAt(token, KindPattern, nil)
+			Insert(a, p)
+
+			return parserMain, p, nil
+
 		case lexer.Separator:
-			p := NewNode(KindPattern, nil)
+			if !inAnyOf(tree) {
+				return nil, tree, errorf(token, "unexpected token: %s", token)
+			}
+
+			p := newNodeAt(token, KindPattern, nil)
 			Insert(tree.Parent, p)
 
 			return parserMain, p, nil
 
+		case lexer.TermsClose:
+			if !inAnyOf(tree) {
+				return nil, tree, errorf(token, "unexpected token: %s", token)
+			}
+
+			return parserMain, tree.Parent.Parent, nil
+
 		default:
-			return nil, tree, fmt.Errorf("unexpected token: %s", token)
+			return nil, tree, errorf(token, "unexpected token: %s", token)
 		}
 	}
 
 	//return nil, tree, fmt.Errorf("unknown error")
 }
+
+func inAnyOf(tree *Node) bool {
+	return tree.Parent != nil && tree.Parent.Kind == KindAnyOf
+}
+
+// parserRange returns parser of the character class opened by the open token.
+func parserRange(open lexer.Token) parseFn {
+	return func(tree *Node, lex Lexer) (parseFn, *Node, error) {
+		var (
+			not   bool
+			lo    rune
+			items []*Node
+		)
+		for {
+			token := lex.Next()
+			switch token.Type {
+			case lexer.EOF:
+				return nil, tree, errorf(open, "unexpected end of pattern: unclosed character class")
+
+			case lexer.Error:
+				return nil, tree, tokenError(token)
+
+			case lexer.Not:
+				not = true
+
+			case lexer.RangeLo:
+				r, w := utf8.DecodeRuneInString(token.Raw)
+				if len(token.Raw) > w {
+					return nil, tree, errorf(token, "unexpected length of lo character")
+				}
+				lo = r
+
+			case lexer.RangeBetween:
+				//
+
+			case lexer.RangeHi:
+				hi, w := utf8.DecodeRuneInString(token.Raw)
+				if len(token.Raw) > w {
+					return nil, tree, errorf(token, "unexpected length of hi character")
+				}
+
+				if hi < lo {
+					return nil, tree, errorf(token, "hi character '%s' should be greater than lo '%s'", string(hi), string(lo))
+				}
+				items = append(items, newNodeAt(token, KindRange, Range{Lo: lo, Hi: hi}))
+
+			case lexer.Text:
+				items = append(items, newNodeAt(token, KindList, List{Chars: token.Raw}))
+
+			case lexer.RangeClose:
+				switch len(items) {
+				case 0:
+					return nil, tree, errorf(open, "could not parse character class")
+
+				case 1:
+					// class of a single list or range is the list or range itself
+					item := items[0]
+					switch v := item.Value.(type) {
+					case List:
+						v.Not = not
+						item.Value = v
+					case Range:
+						v.Not = not
+						item.Value = v
+					}
+					item.Pos = open.Pos
+					Insert(tree, item)
+
+				default:
+					Insert(tree, newNodeAt(open, KindClass, Class{Not: not}, items...))
+				}
+
+				return parserMain, tree, nil
+
+			default:
+				return nil, tree, errorf(token, "unexpected token: %s", token)
+			}
+		}
+	}
+}
@@ -81,6 +81,115 @@ func TestParseString(t *testing.T) {
 				NewNode(KindText, Text{Text: "a?c"}),
 			),
 		},
+		{
+			//pattern: "a?c",
+			testName: "a?c single",
+			tokens: []lexer.Token{
+				{Type: lexer.Text, Raw: "a"},
+				{Type: lexer.Single, Raw: "?"},
+				{Type: lexer.Text, Raw: "c"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindText, Text{Text: "a"}),
+				NewNode(KindSingle, nil),
+				NewNode(KindText, Text{Text: "c"}),
+			),
+		},
+		{
+			//pattern: "a**c",
+			testName: "a**c super",
+			tokens: []lexer.Token{
+				{Type: lexer.Text, Raw: "a"},
+				{Type: lexer.Super, Raw: "**"},
+				{Type: lexer.Text, Raw: "c"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindText, Text{Text: "a"}),
+				NewNode(KindSuper, nil),
+				NewNode(KindText, Text{Text: "c"}),
+			),
+		},
+		{
+			//pattern: "/{z,ab}*",
+			testName: "/{z,ab}* any of",
+			tokens: []lexer.Token{
+				{Type: lexer.Text, Raw: "/"},
+				{Type: lexer.TermsOpen, Raw: "{"},
+				{Type: lexer.Text, Raw: "z"},
+				{Type: lexer.Separator, Raw: ","},
+				{Type: lexer.Text, Raw: "ab"},
+				{Type: lexer.TermsClose, Raw: "}"},
+				{Type: lexer.Any, Raw: "*"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindText, Text{Text: "/"}),
+				NewNode(KindAnyOf, nil,
+					NewNode(KindPattern, nil,
+						NewNode(KindText, Text{Text: "z"}),
+					),
+					NewNode(KindPattern, nil,
+						NewNode(KindText, Text{Text: "ab"}),
+					),
+				),
+				NewNode(KindAny, nil),
+			),
+		},
+		{
+			//pattern: "[!a-z]",
+			testName: "[!a-z] range",
+			tokens: []lexer.Token{
+				{Type: lexer.RangeOpen, Raw: "["},
+				{Type: lexer.Not, Raw: "!"},
+				{Type: lexer.RangeLo, Raw: "a"},
+				{Type: lexer.RangeBetween, Raw: "-"},
+				{Type: lexer.RangeHi, Raw: "z"},
+				{Type: lexer.RangeClose, Raw: "]"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindRange, Range{Not: true, Lo: 'a', Hi: 'z'}),
+			),
+		},
+		{
+			//pattern: "[!a-z0-9_]",
+			testName: "[!a-z0-9_] class",
+			tokens: []lexer.Token{
+				{Type: lexer.RangeOpen, Raw: "["},
+				{Type: lexer.Not, Raw: "!"},
+				{Type: lexer.RangeLo, Raw: "a"},
+				{Type: lexer.RangeBetween, Raw: "-"},
+				{Type: lexer.RangeHi, Raw: "z"},
+				{Type: lexer.RangeLo, Raw: "0"},
+				{Type: lexer.RangeBetween, Raw: "-"},
+				{Type: lexer.RangeHi, Raw: "9"},
+				{Type: lexer.Text, Raw: "_"},
+				{Type: lexer.RangeClose, Raw: "]"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindClass, Class{Not: true},
+					NewNode(KindRange, Range{Lo: 'a', Hi: 'z'}),
+					NewNode(KindRange, Range{Lo: '0', Hi: '9'}),
+					NewNode(KindList, List{Chars: "_"}),
+				),
+			),
+		},
+		{
+			//pattern: "[az]",
+			testName: "[az] list",
+			tokens: []lexer.Token{
+				{Type: lexer.RangeOpen, Raw: "["},
+				{Type: lexer.Text, Raw: "az"},
+				{Type: lexer.RangeClose, Raw: "]"},
+				{Type: lexer.EOF, Raw: ""},
+			},
+			tree: NewNode(KindPattern, nil,
+				NewNode(KindList, List{Chars: "az"}),
+			),
+		},
 		{
 			//pattern: "[!a-z]",
 			testName: "[!a-z]",
@@ -151,3 +260,47 @@ func TestParseString(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePositions(t *testing.T) {
+	tree, err := Parse(lexer.NewLexerMode("ab*[xy]{c,?}", lexer.ModeClasses|lexer.ModeAlternates|lexer.ModeSingle))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var act []int
+	var walk func(*Node)
+	walk = func(n *Node) {
+		act = append(act, n.Pos)
+		for _, c := range n.Children {
+			walk(c)
+		}
+	}
+	walk(tree)
+
+	// Pattern, Text, Any, List, AnyOf, Pattern, Text, Pattern, Single
+	exp := []int{0, 0, 2, 3, 7, 7, 8, 9, 10}
+	if !reflect.DeepEqual(act, exp) {
+		t.Errorf("unexpected positions: exp: %v, act: %v\n%s", exp, act, tree)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for id, test := range []struct {
+		pattern string
+		pos     int
+	}{
+		{"ab[", 2},
+		{"ab[z-a]", 5},
+		{"a{b,c", 1},
+	} {
+		_, err := Parse(lexer.NewLexerMode(test.pattern, lexer.ModeClasses|lexer.ModeAlternates))
+		perr, ok := err.(*Error)
+		if !ok {
+			t.Errorf("#%d %q: expected *Error, got %v", id, test.pattern, err)
+			continue
+		}
+		if perr.Token.Pos != test.pos {
+			t.Errorf("#%d %q: unexpected position: exp: %d, act: %d", id, test.pattern, test.pos, perr.Token.Pos)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package syntax
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gopherlib/simple-glob/syntax/ast"
+	"github.com/gopherlib/simple-glob/syntax/lexer"
+)
+
+// SyntaxError describes a problem in the pattern and its position.
+type SyntaxError struct {
+	Pattern string
+	// Offset is the byte offset of the problem in the pattern.
+	Offset int
+	// Column is the 1-based number of the rune at Offset.
+	Column int
+	// Token is the token the problem was found in. For problems found by
+	// the lexer it is the Error token.
+	Token lexer.Token
+	Msg   string
+}
+
+func newSyntaxError(pattern string, err *ast.Error) *SyntaxError {
+	offset := err.Token.Pos
+	if offset > len(pattern) {
+		offset = len(pattern)
+	}
+	return &SyntaxError{
+		Pattern: pattern,
+		Offset:  offset,
+		Column:  utf8.RuneCountInString(pattern[:offset]) + 1,
+		Token:   err.Token,
+		Msg:     err.Msg,
+	}
+}
+
+// Error renders the message followed by the pattern with a caret under the problem:
+//
+//	unexpected end of pattern: unclosed character class at column 4:
+//		a*b[c
+//		   ^
+func (e *SyntaxError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s at column %d:\n\t%s\n\t", e.Msg, e.Column, e.Pattern)
+	for _, r := range e.Pattern[:e.Offset] {
+		// keep alignment of tabs
+		if r == '\t' {
+			b.WriteByte('\t')
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteByte('^')
+	return b.String()
+}
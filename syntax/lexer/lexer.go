@@ -9,17 +9,72 @@ import (
 )
 
 const (
-	charAny = '*'
+	charAny    = '*'
+	charSingle = '?'
+	charEscape = '\\'
+
+	charRangeOpen    = '['
+	charRangeClose   = ']'
+	charRangeNot     = '!'
+	charRangeNotAlt  = '^'
+	charRangeBetween = '-'
+
+	charTermsOpen  = '{'
+	charTermsClose = '}'
+	charComma      = ','
+)
+
+// Mode is a set of optional syntax features recognized by the lexer.
+// The zero Mode treats only `*` as special.
+type Mode uint
+
+const (
+	// ModeSingle makes `?` match exactly one non-separator character.
+	ModeSingle Mode = 1 << iota
+	// ModeEscape makes `\` escape the following character, so it is matched literally.
+	ModeEscape
+	// ModeSuper makes `**` match any sequence of characters including separators.
+	ModeSuper
+	// ModeClasses makes `[...]` match a single character from a list or a range.
+	ModeClasses
+	// ModeAlternates makes `{a,b,c}` match any of the comma-separated alternatives.
+	ModeAlternates
 )
 
 var specials = []byte{
 	charAny,
+	charSingle,
+	charEscape,
+	charRangeOpen,
+	charRangeClose,
+	charTermsOpen,
+	charTermsClose,
+	charComma,
 }
 
+// Special reports whether c is special in any mode.
 func Special(c byte) bool {
 	return bytes.IndexByte(specials, c) != -1
 }
 
+// SpecialMode reports whether c is special in the mode.
+// The escape character is always reported, as it is special with ModeEscape
+// that is needed to match any other special character literally.
+func SpecialMode(c byte, mode Mode) bool {
+	switch c {
+	case charAny, charEscape:
+		return true
+	case charSingle:
+		return mode&ModeSingle != 0
+	case charRangeOpen, charRangeClose:
+		return mode&ModeClasses != 0
+	case charTermsOpen, charTermsClose, charComma:
+		return mode&ModeAlternates != 0
+	default:
+		return false
+	}
+}
+
 type tokens []Token
 
 func (i *tokens) shift() (ret Token) {
@@ -40,12 +95,18 @@ func (i *tokens) empty() bool {
 var eof rune = 0
 
 type lexer struct {
-	data string
-	pos  int
-	err  error
+	data   string
+	pos    int
+	err    error
+	errPos int
+	mode   Mode
+
+	breakers      []rune
+	termsBreakers []rune
 
-	tokens     tokens
-	termsLevel int
+	tokens tokens
+	// positions of unclosed `{`
+	termsOpen []int
 
 	lastRune     rune
 	lastRuneSize int
@@ -53,16 +114,26 @@ type lexer struct {
 }
 
 func NewLexer(source string) *lexer {
+	return NewLexerMode(source, 0)
+}
+
+// NewLexerMode creates lexer that recognizes syntax features enabled in mode.
+func NewLexerMode(source string, mode Mode) *lexer {
 	l := &lexer{
-		data:   source,
-		tokens: tokens(make([]Token, 0, 4)),
+		data:     source,
+		mode:     mode,
+		breakers: inTextBreakers(mode),
+		tokens:   tokens(make([]Token, 0, 4)),
+	}
+	if l.enabled(ModeAlternates) {
+		l.termsBreakers = append(append([]rune{}, l.breakers...), charTermsClose, charComma)
 	}
 	return l
 }
 
 func (l *lexer) Next() Token {
 	if l.err != nil {
-		return Token{Error, l.err.Error()}
+		return Token{Type: Error, Raw: l.err.Error(), Pos: l.errPos}
 	}
 	if !l.tokens.empty() {
 		return l.tokens.shift()
@@ -79,7 +150,7 @@ func (l *lexer) peek() (r rune, w int) {
 
 	r, w = utf8.DecodeRuneInString(l.data[l.pos:])
 	if r == utf8.RuneError {
-		l.errorf("could not read rune")
+		l.errorf(l.pos, "could not read rune")
 		r = eof
 		w = 0
 	}
@@ -109,64 +180,210 @@ func (l *lexer) seek(w int) {
 
 func (l *lexer) unread() {
 	if l.hasRune {
-		l.errorf("could not unread rune")
+		l.errorf(l.pos, "could not unread rune")
 		return
 	}
 	l.seek(-l.lastRuneSize)
 	l.hasRune = true
 }
 
-func (l *lexer) errorf(f string, v ...interface{}) {
+// errorf sets the error found at pos byte offset of the data.
+func (l *lexer) errorf(pos int, f string, v ...interface{}) {
 	l.err = fmt.Errorf(f, v...)
+	l.errPos = pos
+}
+
+func (l *lexer) emit(t TokenType, raw string, pos int) {
+	l.tokens.push(Token{Type: t, Raw: raw, Pos: pos})
 }
 
 func (l *lexer) inTerms() bool {
-	return l.termsLevel > 0
+	return len(l.termsOpen) > 0
 }
 
-func (l *lexer) termsEnter() {
-	l.termsLevel++
+func (l *lexer) termsEnter(pos int) {
+	l.termsOpen = append(l.termsOpen, pos)
 }
 
 func (l *lexer) termsLeave() {
-	l.termsLevel--
+	l.termsOpen = l.termsOpen[:len(l.termsOpen)-1]
+}
+
+func (l *lexer) enabled(m Mode) bool {
+	return l.mode&m != 0
 }
 
-var inTextBreakers = []rune{charAny}
+func inTextBreakers(mode Mode) []rune {
+	breakers := []rune{charAny}
+	if mode&ModeSingle != 0 {
+		breakers = append(breakers, charSingle)
+	}
+	if mode&ModeClasses != 0 {
+		breakers = append(breakers, charRangeOpen)
+	}
+	if mode&ModeAlternates != 0 {
+		breakers = append(breakers, charTermsOpen)
+	}
+	return breakers
+}
 
 func (l *lexer) fetchItem() {
+	pos := l.pos
 	r := l.read()
 	switch {
 	case r == eof:
-		l.tokens.push(Token{EOF, ""})
+		if l.err != nil {
+			return
+		}
+		if l.inTerms() {
+			l.errorf(l.termsOpen[len(l.termsOpen)-1], "unexpected end of pattern: unclosed alternation")
+			return
+		}
+		l.emit(EOF, "", pos)
 	case r == charAny:
-		l.tokens.push(Token{Any, string(r)})
+		if l.enabled(ModeSuper) {
+			if l.read() == charAny {
+				l.emit(Super, string(charAny)+string(charAny), pos)
+				break
+			}
+			l.unread()
+		}
+		l.emit(Any, string(r), pos)
+	case r == charSingle && l.enabled(ModeSingle):
+		l.emit(Single, string(r), pos)
+	case r == charRangeOpen && l.enabled(ModeClasses):
+		l.emit(RangeOpen, string(r), pos)
+		l.fetchRange(pos)
+	case r == charTermsOpen && l.enabled(ModeAlternates):
+		l.termsEnter(pos)
+		l.emit(TermsOpen, string(r), pos)
+	case r == charComma && l.inTerms():
+		l.emit(Separator, string(r), pos)
+	case r == charTermsClose && l.inTerms():
+		l.emit(TermsClose, string(r), pos)
+		l.termsLeave()
 	default:
 		l.unread()
 
-		l.fetchText(inTextBreakers)
+		if l.inTerms() {
+			l.fetchText(l.termsBreakers)
+		} else {
+			l.fetchText(l.breakers)
+		}
 	}
 }
 
+// fetchRange fetches the character class opened at the open byte offset.
+// The class is a union of characters and lo-hi ranges, such as `[a-z0-9_]`.
+// A `-` that is not between two characters, as the first or the last one, is literal.
+func (l *lexer) fetchRange(open int) {
+	var (
+		chars    []rune
+		charsPos int
+		seenNot  bool
+		seenItem bool
+	)
+	flush := func() {
+		if len(chars) > 0 {
+			l.emit(Text, string(chars), charsPos)
+			chars = nil
+		}
+	}
+	for {
+		pos := l.pos
+		r, escaped, ok := l.readInRange(open)
+		if !ok {
+			return
+		}
+
+		if !escaped && !seenNot && !seenItem && (r == charRangeNot || r == charRangeNotAlt) {
+			l.emit(Not, string(r), pos)
+			seenNot = true
+			continue
+		}
+
+		if !escaped && r == charRangeClose {
+			flush()
+			l.emit(RangeClose, string(r), pos)
+			return
+		}
+		seenItem = true
+
+		if rest := l.data[l.pos:]; len(rest) > 1 && rest[0] == charRangeBetween && rest[1] != charRangeClose {
+			flush()
+			l.emit(RangeLo, string(r), pos)
+			l.emit(RangeBetween, string(charRangeBetween), l.pos)
+			l.seek(1)
+
+			hiPos := l.pos
+			hi, _, ok := l.readInRange(open)
+			if !ok {
+				return
+			}
+			l.emit(RangeHi, string(hi), hiPos)
+			continue
+		}
+
+		if len(chars) == 0 {
+			charsPos = pos
+		}
+		chars = append(chars, r)
+	}
+}
+
+// readInRange reads the next character of the class opened at the open byte
+// offset, unescaping it if needed. It returns false if the error is set.
+func (l *lexer) readInRange(open int) (r rune, escaped, ok bool) {
+	pos := l.pos
+	r = l.read()
+	if r == charEscape && l.enabled(ModeEscape) {
+		escaped = true
+		if r = l.read(); r == eof && l.err == nil {
+			l.errorf(pos, "unexpected end of pattern after escape character")
+		}
+	}
+	if r == eof {
+		if l.err == nil {
+			l.errorf(open, "unexpected end of pattern: unclosed character class")
+		}
+		return eof, escaped, false
+	}
+	return r, escaped, true
+}
+
 func (l *lexer) fetchText(breakers []rune) {
 	var data []rune
+	var escaped bool
+	pos := l.pos
 
 reading:
 	for {
 		r := l.read()
 		if r == eof {
+			if escaped && l.err == nil {
+				l.errorf(l.pos-1, "unexpected end of pattern after escape character")
+				return
+			}
 			break
 		}
 
-		if runes.IndexRune(breakers, r) != -1 {
-			l.unread()
-			break reading
+		if !escaped {
+			if r == charEscape && l.enabled(ModeEscape) {
+				escaped = true
+				continue
+			}
+
+			if runes.IndexRune(breakers, r) != -1 {
+				l.unread()
+				break reading
+			}
 		}
 
+		escaped = false
 		data = append(data, r)
 	}
 
 	if len(data) > 0 {
-		l.tokens.push(Token{Text, string(data)})
+		l.emit(Text, string(data), pos)
 	}
 }
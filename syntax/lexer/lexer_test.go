@@ -7,137 +7,343 @@ import (
 func TestLexGood(t *testing.T) {
 	for id, test := range []struct {
 		pattern string
+		mode    Mode
 		items   []Token
 	}{
 		{
 			pattern: "",
 			items: []Token{
-				{EOF, ""},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: `\*`,
 			items: []Token{
-				{Text, `\`},
-				{Any, "*"},
-				{EOF, ""},
+				{Type: Text, Raw: `\`},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello",
 			items: []Token{
-				{Text, "hello"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "/{rate,[0-9]]}*",
 			items: []Token{
-				{Text, "/{rate,[0-9]]}"},
-				{Any, "*"},
-				{EOF, ""},
+				{Type: Text, Raw: "/{rate,[0-9]]}"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello,world",
 			items: []Token{
-				{Text, "hello,world"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello,world"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello\\,world",
 			items: []Token{
-				{Text, "hello\\,world"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello\\,world"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello\\{world",
 			items: []Token{
-				{Text, "hello\\{world"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello\\{world"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello?",
 			items: []Token{
-				{Text, "hello?"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello?"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hellof*",
 			items: []Token{
-				{Text, "hellof"},
-				{Any, "*"},
-				{EOF, ""},
+				{Type: Text, Raw: "hellof"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "hello**",
 			items: []Token{
-				{Text, "hello"},
-				{Any, "*"},
-				{Any, "*"},
-				{EOF, ""},
+				{Type: Text, Raw: "hello"},
+				{Type: Any, Raw: "*"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "[日-語]",
 			items: []Token{
-				{Text, "[日-語]"},
-				{EOF, ""},
+				{Type: Text, Raw: "[日-語]"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "[!日-語]",
 			items: []Token{
-				{Text, "[!日-語]"},
-				{EOF, ""},
+				{Type: Text, Raw: "[!日-語]"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "[日本語]",
 			items: []Token{
-				{Text, "[日本語]"},
-				{EOF, ""},
+				{Type: Text, Raw: "[日本語]"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "[!日本語]",
 			items: []Token{
-				{Text, "[!日本語]"},
-				{EOF, ""},
+				{Type: Text, Raw: "[!日本語]"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "{a,b}",
 			items: []Token{
-				{Text, "{a,b}"},
-				{EOF, ""},
+				{Type: Text, Raw: "{a,b}"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "/{z,ab}*",
 			items: []Token{
-				{Text, "/{z,ab}"},
-				{Any, "*"},
-				{EOF, ""},
+				{Type: Text, Raw: "/{z,ab}"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
 			},
 		},
 		{
 			pattern: "{[!日-語],*,?,{a,b,\\c}}",
 			items: []Token{
-				{Text, "{[!日-語],"},
-				{Any, "*"},
-				{Text, ",?,{a,b,\\c}}"},
-				{EOF, ""},
+				{Type: Text, Raw: "{[!日-語],"},
+				{Type: Any, Raw: "*"},
+				{Type: Text, Raw: ",?,{a,b,\\c}}"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "hello?",
+			mode:    ModeSingle,
+			items: []Token{
+				{Type: Text, Raw: "hello"},
+				{Type: Single, Raw: "?"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "?a*?",
+			mode:    ModeSingle,
+			items: []Token{
+				{Type: Single, Raw: "?"},
+				{Type: Text, Raw: "a"},
+				{Type: Any, Raw: "*"},
+				{Type: Single, Raw: "?"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: `\*`,
+			mode:    ModeEscape,
+			items: []Token{
+				{Type: Text, Raw: "*"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: `a\\b\*c*`,
+			mode:    ModeEscape,
+			items: []Token{
+				{Type: Text, Raw: `a\b*c`},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: `\??`,
+			mode:    ModeEscape | ModeSingle,
+			items: []Token{
+				{Type: Text, Raw: "?"},
+				{Type: Single, Raw: "?"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: `abc\`,
+			mode:    ModeEscape,
+			items: []Token{
+				{Type: Error, Raw: "unexpected end of pattern after escape character"},
+			},
+		},
+		{
+			pattern: "a***b*",
+			mode:    ModeSuper,
+			items: []Token{
+				{Type: Text, Raw: "a"},
+				{Type: Super, Raw: "**"},
+				{Type: Any, Raw: "*"},
+				{Type: Text, Raw: "b"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "[!日-語]*",
+			mode:    ModeClasses,
+			items: []Token{
+				{Type: RangeOpen, Raw: "["},
+				{Type: Not, Raw: "!"},
+				{Type: RangeLo, Raw: "日"},
+				{Type: RangeBetween, Raw: "-"},
+				{Type: RangeHi, Raw: "語"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: Any, Raw: "*"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "a[^xy!]b",
+			mode:    ModeClasses,
+			items: []Token{
+				{Type: Text, Raw: "a"},
+				{Type: RangeOpen, Raw: "["},
+				{Type: Not, Raw: "^"},
+				{Type: Text, Raw: "xy!"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: Text, Raw: "b"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "[a-z0-9_-]",
+			mode:    ModeClasses,
+			items: []Token{
+				{Type: RangeOpen, Raw: "["},
+				{Type: RangeLo, Raw: "a"},
+				{Type: RangeBetween, Raw: "-"},
+				{Type: RangeHi, Raw: "z"},
+				{Type: RangeLo, Raw: "0"},
+				{Type: RangeBetween, Raw: "-"},
+				{Type: RangeHi, Raw: "9"},
+				{Type: Text, Raw: "_-"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "[!-a-c-]",
+			mode:    ModeClasses,
+			items: []Token{
+				{Type: RangeOpen, Raw: "["},
+				{Type: Not, Raw: "!"},
+				{Type: Text, Raw: "-"},
+				{Type: RangeLo, Raw: "a"},
+				{Type: RangeBetween, Raw: "-"},
+				{Type: RangeHi, Raw: "c"},
+				{Type: Text, Raw: "-"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: `[\]a\-]`,
+			mode:    ModeClasses | ModeEscape,
+			items: []Token{
+				{Type: RangeOpen, Raw: "["},
+				{Type: Text, Raw: "]a-"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "[a-z",
+			mode:    ModeClasses,
+			items: []Token{
+				{Type: Error, Raw: "unexpected end of pattern: unclosed character class"},
+			},
+		},
+		{
+			pattern: "{a,b}",
+			mode:    ModeAlternates,
+			items: []Token{
+				{Type: TermsOpen, Raw: "{"},
+				{Type: Text, Raw: "a"},
+				{Type: Separator, Raw: ","},
+				{Type: Text, Raw: "b"},
+				{Type: TermsClose, Raw: "}"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "a,/{z,ab}*}",
+			mode:    ModeAlternates,
+			items: []Token{
+				{Type: Text, Raw: "a,/"},
+				{Type: TermsOpen, Raw: "{"},
+				{Type: Text, Raw: "z"},
+				{Type: Separator, Raw: ","},
+				{Type: Text, Raw: "ab"},
+				{Type: TermsClose, Raw: "}"},
+				{Type: Any, Raw: "*"},
+				{Type: Text, Raw: "}"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "{[!日-語],*,?,{a,b,\\c}}",
+			mode:    ModeSingle | ModeEscape | ModeClasses | ModeAlternates,
+			items: []Token{
+				{Type: TermsOpen, Raw: "{"},
+				{Type: RangeOpen, Raw: "["},
+				{Type: Not, Raw: "!"},
+				{Type: RangeLo, Raw: "日"},
+				{Type: RangeBetween, Raw: "-"},
+				{Type: RangeHi, Raw: "語"},
+				{Type: RangeClose, Raw: "]"},
+				{Type: Separator, Raw: ","},
+				{Type: Any, Raw: "*"},
+				{Type: Separator, Raw: ","},
+				{Type: Single, Raw: "?"},
+				{Type: Separator, Raw: ","},
+				{Type: TermsOpen, Raw: "{"},
+				{Type: Text, Raw: "a"},
+				{Type: Separator, Raw: ","},
+				{Type: Text, Raw: "b"},
+				{Type: Separator, Raw: ","},
+				{Type: Text, Raw: "c"},
+				{Type: TermsClose, Raw: "}"},
+				{Type: TermsClose, Raw: "}"},
+				{Type: EOF, Raw: ""},
+			},
+		},
+		{
+			pattern: "{a,b",
+			mode:    ModeAlternates,
+			items: []Token{
+				{Type: TermsOpen, Raw: "{"},
+				{Type: Text, Raw: "a"},
+				{Type: Separator, Raw: ","},
+				{Type: Text, Raw: "b"},
+				{Type: Error, Raw: "unexpected end of pattern: unclosed alternation"},
 			},
 		},
 	} {
 		t.Run(test.pattern, func(t *testing.T) {
-			lexer := NewLexer(test.pattern)
+			lexer := NewLexerMode(test.pattern, test.mode)
 			for i, exp := range test.items {
 				act := lexer.Next()
 				if act.Type != exp.Type {
@@ -150,3 +356,25 @@ func TestLexGood(t *testing.T) {
 		})
 	}
 }
+
+func TestLexPositions(t *testing.T) {
+	for id, test := range []struct {
+		pattern string
+		mode    Mode
+		pos     []int
+	}{
+		{pattern: "ab*c", pos: []int{0, 2, 3, 4}},
+		{pattern: "ä**b", mode: ModeSuper, pos: []int{0, 2, 4, 5}},
+		{pattern: `a\*[!x-z]`, mode: ModeEscape | ModeClasses, pos: []int{0, 3, 4, 5, 6, 7, 8, 9}},
+		{pattern: "{a,bc}", mode: ModeAlternates, pos: []int{0, 1, 2, 3, 5, 6}},
+		{pattern: "{a,b", mode: ModeAlternates, pos: []int{0, 1, 2, 3, 0}},
+	} {
+		lexer := NewLexerMode(test.pattern, test.mode)
+		for i, exp := range test.pos {
+			act := lexer.Next()
+			if act.Pos != exp {
+				t.Errorf("#%d %q: wrong %d-th item position: exp: %d; act: %d (%s)", id, test.pattern, i, exp, act.Pos, act)
+			}
+		}
+	}
+}
@@ -10,6 +10,16 @@ const (
 	Text
 	Any
 	Separator
+	Single
+	Super
+	Not
+	RangeOpen
+	RangeClose
+	RangeLo
+	RangeHi
+	RangeBetween
+	TermsOpen
+	TermsClose
 )
 
 func (tt TokenType) String() string {
@@ -29,6 +39,36 @@ func (tt TokenType) String() string {
 	case Separator:
 		return "separator"
 
+	case Single:
+		return "single"
+
+	case Super:
+		return "super"
+
+	case Not:
+		return "not"
+
+	case RangeOpen:
+		return "range_open"
+
+	case RangeClose:
+		return "range_close"
+
+	case RangeLo:
+		return "range_lo"
+
+	case RangeHi:
+		return "range_hi"
+
+	case RangeBetween:
+		return "range_between"
+
+	case TermsOpen:
+		return "terms_open"
+
+	case TermsClose:
+		return "terms_close"
+
 	default:
 		return "undef"
 	}
@@ -37,6 +77,9 @@ func (tt TokenType) String() string {
 type Token struct {
 	Type TokenType
 	Raw  string
+	// Pos is the byte offset of the token in the pattern.
+	// For Error token it is the offset of the error.
+	Pos int
 }
 
 func (t Token) String() string {
@@ -6,9 +6,36 @@ import (
 )
 
 func Parse(s string) (*ast.Node, error) {
-	return ast.Parse(lexer.NewLexer(s))
+	return ParseMode(s, 0)
 }
 
+// ParseMode parses s recognizing optional syntax features enabled in mode.
+func ParseMode(s string, mode lexer.Mode) (*ast.Node, error) {
+	return ParseOptions(s, Options{Mode: mode})
+}
+
+// Options controls how the pattern is parsed.
+type Options struct {
+	// Mode is a set of optional syntax features to recognize.
+	Mode lexer.Mode
+}
+
+// ParseOptions parses s with the given options.
+// Problems in the pattern are reported as *SyntaxError.
+func ParseOptions(s string, opts Options) (*ast.Node, error) {
+	tree, err := ast.Parse(lexer.NewLexerMode(s, opts.Mode))
+	if perr, ok := err.(*ast.Error); ok {
+		return nil, newSyntaxError(s, perr)
+	}
+	return tree, err
+}
+
+// Special reports whether b is special in any mode.
 func Special(b byte) bool {
 	return lexer.Special(b)
 }
+
+// SpecialMode reports whether b is special in the mode, see lexer.SpecialMode.
+func SpecialMode(b byte, mode lexer.Mode) bool {
+	return lexer.SpecialMode(b, mode)
+}
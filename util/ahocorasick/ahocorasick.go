@@ -0,0 +1,115 @@
+// Package ahocorasick implements Aho-Corasick automaton to find
+// all occurrences of many strings in a single pass over the text.
+package ahocorasick
+
+type edge struct {
+	b    byte
+	next int
+}
+
+type node struct {
+	edges []edge
+	fail  int
+	// values of all strings ending at this node, including ones
+	// reachable by failure links
+	out []int
+}
+
+// Automaton finds occurrences of a fixed set of strings.
+type Automaton struct {
+	nodes []node
+}
+
+// New builds automaton for the given strings.
+// Each string is reported with the value at the same index of values.
+// Empty strings are ignored.
+func New(strs []string, values []int) *Automaton {
+	a := &Automaton{nodes: []node{{}}}
+	for i, s := range strs {
+		if s == "" {
+			continue
+		}
+		n := 0
+		for j := 0; j < len(s); j++ {
+			n = a.child(n, s[j])
+		}
+		a.nodes[n].out = append(a.nodes[n].out, values[i])
+	}
+	a.link()
+
+	return a
+}
+
+func (a *Automaton) child(n int, b byte) int {
+	if next := a.goTo(n, b); next != -1 {
+		return next
+	}
+	a.nodes = append(a.nodes, node{})
+	next := len(a.nodes) - 1
+	a.nodes[n].edges = append(a.nodes[n].edges, edge{b, next})
+	return next
+}
+
+func (a *Automaton) goTo(n int, b byte) int {
+	for _, e := range a.nodes[n].edges {
+		if e.b == b {
+			return e.next
+		}
+	}
+	return -1
+}
+
+// link computes failure links in breadth-first order.
+func (a *Automaton) link() {
+	queue := make([]int, 0, len(a.nodes))
+	for _, e := range a.nodes[0].edges {
+		queue = append(queue, e.next)
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+
+		for _, e := range a.nodes[n].edges {
+			f := a.nodes[n].fail
+			for f != 0 && a.goTo(f, e.b) == -1 {
+				f = a.nodes[f].fail
+			}
+			if next := a.goTo(f, e.b); next != -1 && next != e.next {
+				f = next
+			} else {
+				f = 0
+			}
+
+			child := &a.nodes[e.next]
+			child.fail = f
+			child.out = append(child.out, a.nodes[f].out...)
+
+			queue = append(queue, e.next)
+		}
+	}
+}
+
+func (a *Automaton) step(n int, b byte) int {
+	for {
+		if next := a.goTo(n, b); next != -1 {
+			return next
+		}
+		if n == 0 {
+			return 0
+		}
+		n = a.nodes[n].fail
+	}
+}
+
+// Each calls fn with the value of each string found in s.
+// The same value may be reported several times.
+func (a *Automaton) Each(s string, fn func(value int)) {
+	var n int
+	for i := 0; i < len(s); i++ {
+		n = a.step(n, s[i])
+		for _, v := range a.nodes[n].out {
+			fn(v)
+		}
+	}
+}
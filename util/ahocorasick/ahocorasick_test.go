@@ -0,0 +1,61 @@
+package ahocorasick
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestAutomatonEach(t *testing.T) {
+	for id, test := range []struct {
+		strs []string
+		s    string
+		exp  []int
+	}{
+		{[]string{"he", "she", "his", "hers"}, "ushers", []int{0, 1, 3}},
+		{[]string{"a", "ab", "bab", "bc", "bca", "c", "caa"}, "abccab", []int{0, 1, 3, 5}},
+		{[]string{"abc", "x"}, "ababd", nil},
+		{[]string{"", "a"}, "a", []int{1}},
+		{[]string{".com", "example.com", "com"}, "api.example.com", []int{0, 1, 2}},
+		{[]string{"日本", "本"}, "日本語", []int{0, 1}},
+	} {
+		values := make([]int, len(test.strs))
+		for i := range values {
+			values[i] = i
+		}
+
+		var act []int
+		New(test.strs, values).Each(test.s, func(v int) {
+			act = append(act, v)
+		})
+		act = unique(act)
+
+		if exp := bruteForce(test.strs, test.s); !reflect.DeepEqual(exp, test.exp) {
+			t.Fatalf("#%d bad test case: brute force gives %v", id, exp)
+		}
+		if !reflect.DeepEqual(act, test.exp) {
+			t.Errorf("#%d unexpected values: exp: %v, act: %v", id, test.exp, act)
+		}
+	}
+}
+
+func bruteForce(strs []string, s string) (values []int) {
+	for i, str := range strs {
+		if str != "" && strings.Contains(s, str) {
+			values = append(values, i)
+		}
+	}
+	return values
+}
+
+func unique(values []int) []int {
+	sort.Ints(values)
+	var result []int
+	for i, v := range values {
+		if i == 0 || v != values[i-1] {
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,9 @@
+package strings
+
+import "unsafe"
+
+// FromBytes returns string sharing memory with b, without copying it.
+// The string must not be retained after b is modified.
+func FromBytes(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
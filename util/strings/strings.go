@@ -2,12 +2,22 @@ package strings
 
 import (
 	"strings"
+	"unicode"
 	"unicode/utf8"
 )
 
+// IndexAnyRunes returns the index of the first instance of any rune from rs in s,
+// or -1 if none of rs is present in s.
 func IndexAnyRunes(s string, rs []rune) int {
-	for _, r := range rs {
-		if i := strings.IndexRune(s, r); i != -1 {
+	switch len(rs) {
+	case 0:
+		return -1
+	case 1:
+		return strings.IndexRune(s, rs[0])
+	}
+
+	for i, c := range s {
+		if containsRune(rs, c) {
 			return i
 		}
 	}
@@ -15,25 +25,181 @@ func IndexAnyRunes(s string, rs []rune) int {
 	return -1
 }
 
+// LastIndexAnyRunes returns the index of the last instance of any rune from rs in s,
+// or -1 if none of rs is present in s.
 func LastIndexAnyRunes(s string, rs []rune) int {
+	switch {
+	case len(rs) == 0:
+		return -1
+	case len(rs) == 1 && 0 <= rs[0] && rs[0] < utf8.RuneSelf:
+		return strings.LastIndexByte(s, byte(rs[0]))
+	}
+
+	for i := len(s); i > 0; {
+		c, w := utf8.DecodeLastRuneInString(s[:i])
+		i -= w
+		if containsRune(rs, c) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func containsRune(rs []rune, c rune) bool {
 	for _, r := range rs {
-		i := -1
-		if 0 <= r && r < utf8.RuneSelf {
-			i = strings.LastIndexByte(s, byte(r))
-		} else {
-			sub := s
-			for len(sub) > 0 {
-				j := strings.IndexRune(s, r)
-				if j == -1 {
-					break
-				}
-				i = j
-				sub = sub[i+1:]
+		if r == c {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPrefixFold reports whether s begins with prefix under Unicode simple case folding.
+// It also returns the length of the prefix in s, which may differ from len(prefix).
+func HasPrefixFold(s, prefix string) (int, bool) {
+	var i, j int
+	for j < len(prefix) {
+		if i >= len(s) {
+			return 0, false
+		}
+
+		// ASCII fast path
+		if a, b := s[i], prefix[j]; a < utf8.RuneSelf && b < utf8.RuneSelf {
+			if a != b && lowerASCII(a) != lowerASCII(b) {
+				return 0, false
 			}
+			i++
+			j++
+			continue
 		}
-		if i != -1 {
-			return i
+
+		a, wa := utf8.DecodeRuneInString(s[i:])
+		b, wb := utf8.DecodeRuneInString(prefix[j:])
+		if !EqualFoldRune(a, b) {
+			return 0, false
 		}
+		i += wa
+		j += wb
 	}
-	return -1
+
+	return i, true
+}
+
+// HasSuffixFold reports whether s ends with suffix under Unicode simple case folding.
+// It also returns the length of the suffix in s, which may differ from len(suffix).
+func HasSuffixFold(s, suffix string) (int, bool) {
+	i, j := len(s), len(suffix)
+	for j > 0 {
+		if i <= 0 {
+			return 0, false
+		}
+
+		// ASCII fast path
+		if a, b := s[i-1], suffix[j-1]; a < utf8.RuneSelf && b < utf8.RuneSelf {
+			if a != b && lowerASCII(a) != lowerASCII(b) {
+				return 0, false
+			}
+			i--
+			j--
+			continue
+		}
+
+		a, wa := utf8.DecodeLastRuneInString(s[:i])
+		b, wb := utf8.DecodeLastRuneInString(suffix[:j])
+		if !EqualFoldRune(a, b) {
+			return 0, false
+		}
+		i -= wa
+		j -= wb
+	}
+
+	return len(s) - i, true
+}
+
+// IndexFold returns the index of the first instance of substr in s under
+// Unicode simple case folding and the length of that instance, or -1 if substr is not present in s.
+func IndexFold(s, substr string) (index, length int) {
+	for i := range s {
+		if n, ok := HasPrefixFold(s[i:], substr); ok {
+			return i, n
+		}
+	}
+	if len(substr) == 0 {
+		return len(s), 0
+	}
+
+	return -1, 0
+}
+
+// LastIndexFold returns the index of the last instance of substr in s under
+// Unicode simple case folding and the length of that instance, or -1 if substr is not present in s.
+func LastIndexFold(s, substr string) (index, length int) {
+	for i := len(s); i >= 0; i-- {
+		if i < len(s) && !utf8.RuneStart(s[i]) {
+			continue
+		}
+		if n, ok := HasPrefixFold(s[i:], substr); ok {
+			return i, n
+		}
+	}
+
+	return -1, 0
+}
+
+// EqualFoldRune reports whether a and b are equal under Unicode simple case folding.
+func EqualFoldRune(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	if a < b {
+		a, b = b, a
+	}
+
+	// SimpleFold returns the next equivalent rune greater than given one
+	// or wraps around to smaller values
+	r := unicode.SimpleFold(b)
+	for r != b && r < a {
+		r = unicode.SimpleFold(r)
+	}
+
+	return r == a
+}
+
+// CommonPrefix returns the longest common prefix of a and b
+// that does not end in the middle of a rune.
+func CommonPrefix(a, b string) string {
+	var i int
+	for i < len(a) && i < len(b) {
+		ra, w := utf8.DecodeRuneInString(a[i:])
+		rb, _ := utf8.DecodeRuneInString(b[i:])
+		if ra != rb {
+			break
+		}
+		i += w
+	}
+	return a[:i]
+}
+
+// CommonSuffix returns the longest common suffix of a and b
+// that does not start in the middle of a rune.
+func CommonSuffix(a, b string) string {
+	i, j := len(a), len(b)
+	for i > 0 && j > 0 {
+		ra, wa := utf8.DecodeLastRuneInString(a[:i])
+		rb, wb := utf8.DecodeLastRuneInString(b[:j])
+		if ra != rb {
+			break
+		}
+		i -= wa
+		j -= wb
+	}
+	return a[i:]
+}
+
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
 }
@@ -0,0 +1,158 @@
+package strings
+
+import (
+	"testing"
+)
+
+func TestIndexAnyRunes(t *testing.T) {
+	for id, test := range []struct {
+		s     string
+		rs    []rune
+		index int
+	}{
+		{"abc", nil, -1},
+		{"abc", []rune{'c'}, 2},
+		{"a/b.c", []rune{'.', '/'}, 1},
+		{"日本.語", []rune{'語', '.'}, 6},
+		{"abc", []rune{'x', 'y'}, -1},
+	} {
+		if act := IndexAnyRunes(test.s, test.rs); act != test.index {
+			t.Errorf("#%d IndexAnyRunes(%q, %q) = %d; want %d", id, test.s, string(test.rs), act, test.index)
+		}
+	}
+}
+
+func TestLastIndexAnyRunes(t *testing.T) {
+	for id, test := range []struct {
+		s     string
+		rs    []rune
+		index int
+	}{
+		{"abc", nil, -1},
+		{"a.b.c", []rune{'.'}, 3},
+		{"a.b/c", []rune{'/', '.'}, 3},
+		{"a.b/c", []rune{'.', '/'}, 3},
+		{"日本日本", []rune{'日'}, 6},
+		{"abc", []rune{'x', 'y'}, -1},
+	} {
+		if act := LastIndexAnyRunes(test.s, test.rs); act != test.index {
+			t.Errorf("#%d LastIndexAnyRunes(%q, %q) = %d; want %d", id, test.s, string(test.rs), act, test.index)
+		}
+	}
+}
+
+func TestHasPrefixFold(t *testing.T) {
+	for id, test := range []struct {
+		s, prefix string
+		n         int
+		ok        bool
+	}{
+		{"abc", "", 0, true},
+		{"ABCdef", "abc", 3, true},
+		{"ab", "abc", 0, false},
+		{"abd", "abc", 0, false},
+		{"ſun", "S", 2, true},
+		{"Straße", "STRA", 4, true},
+		{"Kelvin", "k", 3, true},
+	} {
+		n, ok := HasPrefixFold(test.s, test.prefix)
+		if n != test.n || ok != test.ok {
+			t.Errorf("#%d HasPrefixFold(%q, %q) = %d, %v; want %d, %v", id, test.s, test.prefix, n, ok, test.n, test.ok)
+		}
+	}
+}
+
+func TestHasSuffixFold(t *testing.T) {
+	for id, test := range []struct {
+		s, suffix string
+		n         int
+		ok        bool
+	}{
+		{"abc", "", 0, true},
+		{"example.COM", ".com", 4, true},
+		{"bc", "abc", 0, false},
+		{"buſ", "S", 2, true},
+		{"σίσυφοσ", "Σ", 2, true},
+	} {
+		n, ok := HasSuffixFold(test.s, test.suffix)
+		if n != test.n || ok != test.ok {
+			t.Errorf("#%d HasSuffixFold(%q, %q) = %d, %v; want %d, %v", id, test.s, test.suffix, n, ok, test.n, test.ok)
+		}
+	}
+}
+
+func TestIndexFold(t *testing.T) {
+	for id, test := range []struct {
+		s, substr string
+		index     int
+		length    int
+		last      int
+	}{
+		{"abc", "", 0, 0, 3},
+		{"xABcab", "ab", 1, 2, 4},
+		{"mAſs", "ss", 2, 3, 2},
+		{"日本語", "本", 3, 3, 3},
+		{"abc", "d", -1, 0, -1},
+	} {
+		if index, length := IndexFold(test.s, test.substr); index != test.index || length != test.length {
+			t.Errorf("#%d IndexFold(%q, %q) = %d, %d; want %d, %d", id, test.s, test.substr, index, length, test.index, test.length)
+		}
+		if index, _ := LastIndexFold(test.s, test.substr); index != test.last {
+			t.Errorf("#%d LastIndexFold(%q, %q) = %d; want %d", id, test.s, test.substr, index, test.last)
+		}
+	}
+}
+
+func TestEqualFoldRune(t *testing.T) {
+	for id, test := range []struct {
+		a, b rune
+		eq   bool
+	}{
+		{'a', 'a', true},
+		{'a', 'A', true},
+		{'k', 'K', true},
+		{'K', 'K', true},
+		{'s', 'ſ', true},
+		{'a', 'b', false},
+		{'σ', 'ς', true},
+	} {
+		if act := EqualFoldRune(test.a, test.b); act != test.eq {
+			t.Errorf("#%d EqualFoldRune(%q, %q) = %v; want %v", id, test.a, test.b, act, test.eq)
+		}
+	}
+}
+
+func TestCommonPrefixSuffix(t *testing.T) {
+	for id, test := range []struct {
+		a, b           string
+		prefix, suffix string
+	}{
+		{"api.prod", "api.staging", "api.", ""},
+		{"a.jpg", "b.jpg", "", ".jpg"},
+		{"abc", "abc", "abc", "abc"},
+		{"", "abc", "", ""},
+		// different runes sharing the first or the last byte
+		{"日本", "日朝", "日", ""},
+		{"ä", "ö", "", ""},
+	} {
+		if act := CommonPrefix(test.a, test.b); act != test.prefix {
+			t.Errorf("#%d CommonPrefix(%q, %q) = %q; want %q", id, test.a, test.b, act, test.prefix)
+		}
+		if act := CommonSuffix(test.a, test.b); act != test.suffix {
+			t.Errorf("#%d CommonSuffix(%q, %q) = %q; want %q", id, test.a, test.b, act, test.suffix)
+		}
+	}
+}
+
+func TestFromBytes(t *testing.T) {
+	b := []byte("abc")
+	if s := FromBytes(b); s != "abc" {
+		t.Errorf("unexpected string: %q", s)
+	}
+	if s := FromBytes(nil); s != "" {
+		t.Errorf("unexpected string: %q", s)
+	}
+	if n := testing.AllocsPerRun(10, func() { _ = FromBytes(b) }); n != 0 {
+		t.Errorf("unexpected allocations: %v", n)
+	}
+}
@@ -0,0 +1,285 @@
+package glob
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gopherlib/simple-glob/match"
+)
+
+// WalkOptions controls how GlobFSParallel walks the file system.
+type WalkOptions struct {
+	// Workers is the number of goroutines reading directories.
+	// Zero means runtime.GOMAXPROCS(0).
+	Workers int
+	// Sorted makes GlobFSParallel collect all matching paths first
+	// and pass them in the same order as GlobFS would.
+	Sorted bool
+}
+
+// GlobFSParallel is the same as GlobFS, except that directories are read
+// concurrently by a bounded pool of workers, see WalkOptions.
+//
+// The fn function is always called from the calling goroutine. Paths are passed
+// to it in no particular order, unless walk.Sorted is set.
+// Returning fs.SkipDir from fn is not supported; any non-nil error stops the walk
+// and is returned. The walk also stops when ctx is done, returning ctx.Err().
+func GlobFSParallel(ctx context.Context, fsys fs.FS, pattern string, fn func(name string, d fs.DirEntry) error, walk WalkOptions, opts ...Option) error {
+	opts = append(opts[:len(opts):len(opts)], WithSeparators('/'))
+	g, err := CompileOptions(pattern, opts...)
+	if err != nil {
+		return err
+	}
+
+	parent := ctx
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	w := &walker{
+		ctx:     ctx,
+		fsys:    fsys,
+		matcher: g.(glob).matcher,
+		results: make(chan walkResult, 64),
+	}
+	w.cond = sync.NewCond(&w.mu)
+
+	root := walkRoot(w.matcher)
+
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if !info.IsDir() {
+		if root != "." && w.matcher.Match(root) {
+			return fn(root, fs.FileInfoToDirEntry(info))
+		}
+		return nil
+	}
+
+	workers := walk.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	w.push(root)
+	go w.wakeOnDone()
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.work()
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(w.results)
+	}()
+
+	var sorted []walkResult
+	for r := range w.results {
+		if ctx.Err() != nil {
+			break
+		}
+		if walk.Sorted {
+			sorted = append(sorted, r)
+			continue
+		}
+		if err := fn(r.name, r.d); err != nil {
+			w.fail(err)
+			break
+		}
+	}
+	cancel()
+	// drain results left by workers that are stopping
+	for range w.results {
+	}
+
+	if err := w.error(); err != nil {
+		return err
+	}
+	if err := parent.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return comparePaths(sorted[i].name, sorted[j].name) < 0
+	})
+	for _, r := range sorted {
+		if err := parent.Err(); err != nil {
+			return err
+		}
+		if err := fn(r.name, r.d); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type walkResult struct {
+	name string
+	d    fs.DirEntry
+}
+
+type walker struct {
+	ctx     context.Context
+	fsys    fs.FS
+	matcher match.Matcher
+	results chan walkResult
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	queue   []string
+	pending int
+	stopped bool
+	err     error
+}
+
+// push adds directory to the queue of directories to read.
+func (w *walker) push(dir string) {
+	w.mu.Lock()
+	w.queue = append(w.queue, dir)
+	w.pending++
+	w.mu.Unlock()
+	w.cond.Signal()
+}
+
+// pop returns the next directory to read. It blocks until there is one,
+// and returns false when all directories are read or the walk is stopped.
+func (w *walker) pop() (string, bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for len(w.queue) == 0 && w.pending > 0 && !w.stopped {
+		w.cond.Wait()
+	}
+	if len(w.queue) == 0 || w.stopped {
+		return "", false
+	}
+
+	// reading the last pushed directory first keeps the queue short
+	last := len(w.queue) - 1
+	dir := w.queue[last]
+	w.queue = w.queue[:last]
+
+	return dir, true
+}
+
+// done marks directory returned by pop as read.
+func (w *walker) done() {
+	w.mu.Lock()
+	w.pending--
+	if w.pending == 0 {
+		w.cond.Broadcast()
+	}
+	w.mu.Unlock()
+}
+
+// fail stops the walk with the error, unless it is already stopped with another one.
+func (w *walker) fail(err error) {
+	w.mu.Lock()
+	if w.err == nil {
+		w.err = err
+	}
+	w.stopped = true
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+func (w *walker) error() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+func (w *walker) wakeOnDone() {
+	<-w.ctx.Done()
+	w.mu.Lock()
+	w.stopped = true
+	w.mu.Unlock()
+	w.cond.Broadcast()
+}
+
+func (w *walker) work() {
+	for {
+		dir, ok := w.pop()
+		if !ok {
+			return
+		}
+		if err := w.read(dir); err != nil {
+			w.fail(err)
+		}
+		w.done()
+	}
+}
+
+// read reads the directory, sends its matching entries to results
+// and pushes subdirectories that could contain matching paths.
+func (w *walker) read(dir string) error {
+	if err := w.ctx.Err(); err != nil {
+		return nil
+	}
+
+	entries, err := fs.ReadDir(w.fsys, dir)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range entries {
+		name := path.Join(dir, d.Name())
+
+		if d.IsDir() && w.matcher.CouldMatchPrefix(name+"/") {
+			w.push(name)
+		}
+
+		if w.matcher.Match(name) {
+			select {
+			case w.results <- walkResult{name, d}:
+			case <-w.ctx.Done():
+				return nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// comparePaths compares slash-separated paths in the order fs.WalkDir visits them.
+func comparePaths(a, b string) int {
+	for {
+		i := strings.IndexByte(a, '/')
+		j := strings.IndexByte(b, '/')
+
+		ha, hb := a, b
+		if i != -1 {
+			ha = a[:i]
+		}
+		if j != -1 {
+			hb = b[:j]
+		}
+		if ha != hb {
+			return strings.Compare(ha, hb)
+		}
+
+		switch {
+		case i == -1 && j == -1:
+			return 0
+		case i == -1:
+			return -1
+		case j == -1:
+			return 1
+		}
+		a, b = a[i+1:], b[j+1:]
+	}
+}
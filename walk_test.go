@@ -0,0 +1,146 @@
+package glob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"sort"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGlobFSParallel(t *testing.T) {
+	for _, test := range []struct {
+		pattern  string
+		features Feature
+	}{
+		{pattern: "*.go"},
+		{pattern: "*/*/*.go"},
+		{pattern: "pkg/api*/*.go"},
+		{pattern: "**/*.go", features: Super},
+		{pattern: "**", features: Super},
+		{pattern: "docs/images/{*.png,日本/*}", features: Alternates},
+		{pattern: "main.go"},
+		{pattern: "cmd/tool/main.go"},
+		{pattern: "missing/*.go"},
+	} {
+		for _, workers := range []int{0, 1, 4} {
+			t.Run(fmt.Sprintf("%s/%d", test.pattern, workers), func(t *testing.T) {
+				var exp []string
+				err := GlobFS(testFS, test.pattern, func(name string, d fs.DirEntry) error {
+					exp = append(exp, name)
+					return nil
+				}, WithFeatures(test.features))
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var sorted []string
+				err = GlobFSParallel(context.Background(), testFS, test.pattern, func(name string, d fs.DirEntry) error {
+					sorted = append(sorted, name)
+					return nil
+				}, WalkOptions{Workers: workers, Sorted: true}, WithFeatures(test.features))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				if !reflect.DeepEqual(sorted, exp) {
+					t.Errorf("unexpected sorted matches: exp: %v, act: %v", exp, sorted)
+				}
+
+				var unsorted []string
+				err = GlobFSParallel(context.Background(), testFS, test.pattern, func(name string, d fs.DirEntry) error {
+					unsorted = append(unsorted, name)
+					return nil
+				}, WalkOptions{Workers: workers}, WithFeatures(test.features))
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				sort.Slice(unsorted, func(i, j int) bool {
+					return comparePaths(unsorted[i], unsorted[j]) < 0
+				})
+				if !reflect.DeepEqual(unsorted, exp) {
+					t.Errorf("unexpected matches: exp: %v, act: %v", exp, unsorted)
+				}
+			})
+		}
+	}
+}
+
+func TestGlobFSParallelStop(t *testing.T) {
+	fsys := fstest.MapFS{}
+	for i := 0; i < 100; i++ {
+		for j := 0; j < 10; j++ {
+			fsys[fmt.Sprintf("d%d/f%d.go", i, j)] = &fstest.MapFile{}
+		}
+	}
+
+	t.Run("error", func(t *testing.T) {
+		stop := errors.New("stop")
+		var n int
+		err := GlobFSParallel(context.Background(), fsys, "*/*.go", func(name string, d fs.DirEntry) error {
+			n++
+			if n == 10 {
+				return stop
+			}
+			return nil
+		}, WalkOptions{Workers: 4})
+		if err != stop {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if n != 10 {
+			t.Errorf("fn called %d times after error", n)
+		}
+	})
+
+	t.Run("cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var n int
+		err := GlobFSParallel(ctx, fsys, "*/*.go", func(name string, d fs.DirEntry) error {
+			n++
+			if n == 10 {
+				cancel()
+			}
+			return nil
+		}, WalkOptions{Workers: 4})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: %v", err)
+		}
+		if n != 10 {
+			t.Errorf("fn called %d times after cancel", n)
+		}
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := GlobFSParallel(ctx, fsys, "*/*.go", func(name string, d fs.DirEntry) error {
+			return nil
+		}, WalkOptions{Sorted: true})
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("unexpected error: %v", err)
+		}
+	})
+}
+
+func TestComparePaths(t *testing.T) {
+	for id, test := range []struct {
+		a, b string
+		exp  int
+	}{
+		{"a", "a", 0},
+		{"a", "b", -1},
+		{"a", "a/b", -1},
+		{"a/b", "a.go", -1},
+		{"a/b/c", "a/b", 1},
+		{"a/c", "a/b/c", 1},
+	} {
+		if act := comparePaths(test.a, test.b); act != test.exp {
+			t.Errorf("#%d comparePaths(%q, %q) = %d; want %d", id, test.a, test.b, act, test.exp)
+		}
+	}
+}